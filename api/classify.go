@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	gmerrors "github.com/graymeta/gmkit/errors"
+)
+
+// Error types reported by ClassifyError.
+const (
+	ErrorTypeBadRequest          = "BadRequest"
+	ErrorTypeUnauthorized        = "Unauthorized"
+	ErrorTypeForbidden           = "Forbidden"
+	ErrorTypeNotFound            = "NotFound"
+	ErrorTypeExists              = "Exists"
+	ErrorTypeConflict            = "Conflict"
+	ErrorTypeValidationFailed    = "ValidationFailed"
+	ErrorTypeTooManyRequests     = "TooManyRequests"
+	ErrorTypeUnavailable         = "Unavailable"
+	ErrorTypeInternalServerError = "InternalServerError"
+)
+
+// ErrorClass describes how an error is reported to clients, by Err for a
+// single request, and by the bulk package for each item of a bulk request.
+type ErrorClass struct {
+	// Type is the category of the error.
+	Type string
+	// Status is the HTTP status code of the error.
+	Status int
+	// Message is the message reported to clients, or empty if the error's own
+	// message is safe to report.
+	Message string
+
+	// quiet is set for errors that are expected and not worth logging.
+	quiet bool
+}
+
+var statusTypes = map[int]string{
+	http.StatusBadRequest:          ErrorTypeBadRequest,
+	http.StatusUnauthorized:        ErrorTypeUnauthorized,
+	http.StatusForbidden:           ErrorTypeForbidden,
+	http.StatusNotFound:            ErrorTypeNotFound,
+	http.StatusConflict:            ErrorTypeConflict,
+	http.StatusUnprocessableEntity: ErrorTypeValidationFailed,
+	http.StatusTooManyRequests:     ErrorTypeTooManyRequests,
+	http.StatusServiceUnavailable:  ErrorTypeUnavailable,
+}
+
+// classifiers are tried in order until one classifies the error. Behaviors are
+// checked rather than types, and errors may report false for a behavior they
+// implement, most usefully a PGErr that fulfills more than one behavior.
+var classifiers = []func(err error) (ErrorClass, bool){
+	func(err error) (ErrorClass, bool) {
+		var e *ErrValidationFailed
+		if !errors.As(err, &e) {
+			return ErrorClass{}, false
+		}
+		return ErrorClass{Type: ErrorTypeValidationFailed, Status: e.StatusCode()}, true
+	},
+	func(err error) (ErrorClass, bool) {
+		var e *ErrJSON
+		if !errors.As(err, &e) {
+			return ErrorClass{}, false
+		}
+		return ErrorClass{Type: ErrorTypeBadRequest, Status: e.StatusCode()}, true
+	},
+	func(err error) (ErrorClass, bool) {
+		var e gmerrors.HTTP
+		if !errors.As(err, &e) {
+			return ErrorClass{}, false
+		}
+		typ, ok := statusTypes[e.StatusCode()]
+		if !ok {
+			typ = ErrorTypeInternalServerError
+		}
+		class := ErrorClass{Type: typ, Status: e.StatusCode()}
+		// only the message of an HTTP error returned as is may be reported, an
+		// error wrapping one, such as an httpc ClientErr, may hold an upstream's.
+		if _, ok := err.(gmerrors.HTTP); !ok {
+			class.Message = strings.ToLower(http.StatusText(e.StatusCode()))
+			if class.Message == "" {
+				class.Message = "internal server error"
+			}
+		}
+		return class, true
+	},
+	func(err error) (ErrorClass, bool) {
+		var e gmerrors.NotFounder
+		if !errors.As(err, &e) || !e.NotFound() {
+			return ErrorClass{}, false
+		}
+		return ErrorClass{Type: ErrorTypeNotFound, Status: http.StatusNotFound, Message: "resource not found", quiet: true}, true
+	},
+	func(err error) (ErrorClass, bool) {
+		var e gmerrors.Exister
+		if !errors.As(err, &e) || !e.Exists() {
+			return ErrorClass{}, false
+		}
+		return ErrorClass{Type: ErrorTypeExists, Status: http.StatusUnprocessableEntity, Message: "resource exists"}, true
+	},
+	func(err error) (ErrorClass, bool) {
+		var e gmerrors.Conflicter
+		if !errors.As(err, &e) || !e.Conflict() {
+			return ErrorClass{}, false
+		}
+		return ErrorClass{Type: ErrorTypeConflict, Status: http.StatusUnprocessableEntity, Message: "resource conflict"}, true
+	},
+	func(err error) (ErrorClass, bool) {
+		var e gmerrors.Temporarier
+		if !errors.As(err, &e) || !e.Temporary() {
+			return ErrorClass{}, false
+		}
+		return ErrorClass{Type: ErrorTypeUnavailable, Status: http.StatusServiceUnavailable, Message: "service unavailable"}, true
+	},
+	func(err error) (ErrorClass, bool) {
+		var e gmerrors.Retrier
+		if !errors.As(err, &e) || !e.Retry() {
+			return ErrorClass{}, false
+		}
+		return ErrorClass{Type: ErrorTypeUnavailable, Status: http.StatusServiceUnavailable, Message: "service unavailable"}, true
+	},
+}
+
+// ClassifyError returns the class of an error from the behaviors it, or any
+// error it wraps, exhibits. Errors without a recognized behavior are internal
+// server errors.
+func ClassifyError(err error) ErrorClass {
+	for _, classify := range classifiers {
+		if class, ok := classify(err); ok {
+			return class
+		}
+	}
+	return ErrorClass{Type: ErrorTypeInternalServerError, Status: http.StatusInternalServerError, Message: "internal server error"}
+}
@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graymeta/gmkit/api"
+	gmerrors "github.com/graymeta/gmkit/errors"
+	"github.com/graymeta/gmkit/http/httpc"
+	"github.com/graymeta/gmkit/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type behaviorErr struct {
+	notFound, exists, conflict, temporary, retry bool
+}
+
+func (e behaviorErr) Error() string   { return "behavior" }
+func (e behaviorErr) NotFound() bool  { return e.notFound }
+func (e behaviorErr) Exists() bool    { return e.exists }
+func (e behaviorErr) Conflict() bool  { return e.conflict }
+func (e behaviorErr) Temporary() bool { return e.temporary }
+func (e behaviorErr) Retry() bool     { return e.retry }
+
+func TestErr(t *testing.T) {
+	tests := []struct {
+		description string
+		err         error
+		status      int
+		message     string
+	}{
+		{"http error", &gmerrors.HTTPErr{Msg: "slow down", Code: http.StatusTooManyRequests}, http.StatusTooManyRequests, "slow down"},
+		{"wrapped http error", fmt.Errorf("limiting: %w", &gmerrors.HTTPErr{Msg: "slow down", Code: http.StatusTooManyRequests}), http.StatusTooManyRequests, "too many requests"},
+		{"not found", behaviorErr{notFound: true}, http.StatusNotFound, "resource not found"},
+		{"wrapped not found", fmt.Errorf("reading: %w", behaviorErr{notFound: true}), http.StatusNotFound, "resource not found"},
+		{"exists", behaviorErr{exists: true}, http.StatusUnprocessableEntity, "resource exists"},
+		{"conflict", behaviorErr{conflict: true}, http.StatusUnprocessableEntity, "resource conflict"},
+		{"temporary", behaviorErr{temporary: true}, http.StatusServiceUnavailable, "service unavailable"},
+		{"retry", behaviorErr{retry: true}, http.StatusServiceUnavailable, "service unavailable"},
+		{"no behavior", behaviorErr{}, http.StatusInternalServerError, "internal server error"},
+		{"plain error", errors.New("boom"), http.StatusInternalServerError, "internal server error"},
+	}
+
+	respond := api.NewResponder(logger.Silence(), "v2")
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			respond.Err(w, httptest.NewRequest("GET", "/", nil), tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.status, api.ClassifyError(tt.err).Status)
+			var decoded api.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&decoded))
+			assert.Equal(t, tt.message, decoded.Error.Message)
+		})
+	}
+}
+
+func TestErrWrappedClientErr(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"no such bucket on storage-7.internal"}`))
+	}))
+	defer upstream.Close()
+
+	err := httpc.New(upstream.Client()).
+		GET(upstream.URL).
+		OnError(func(r io.Reader) error {
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.NewDecoder(r).Decode(&body); err != nil {
+				return err
+			}
+			return &gmerrors.HTTPErr{Msg: body.Error, Code: http.StatusNotFound}
+		}).
+		Do(context.Background())
+	require.Error(t, err)
+
+	w := httptest.NewRecorder()
+	api.NewResponder(logger.Silence(), "v2").Err(w, httptest.NewRequest("GET", "/", nil), err)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "storage-7")
+	var decoded api.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&decoded))
+	assert.Equal(t, "not found", decoded.Error.Message)
+}
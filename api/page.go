@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/graymeta/gmkit/pagetoken"
+)
+
+// PageResponse is the body of a page of a list served up by Page.
+type PageResponse struct {
+	Items         interface{} `json:"items"`
+	NextPageToken string      `json:"next_page_token"`
+	PrevPageToken string      `json:"prev_page_token"`
+	Total         *int        `json:"total,omitempty"`
+}
+
+// Page responds with status code OK and a page of items, which must be a
+// slice, selected by token from a list of total items. Total is omitted from
+// the response when it is pagetoken.TotalTODO. The tokens of the next and
+// previous pages are included in the response, and as links to the request
+// URL with the page token replaced in the Link header, as described by RFC
+// 5988. For keyset tokens, the next token is generated from the last item, so
+// the items must implement pagetoken.Keyer; Page responds with an internal
+// server error if they do not.
+func (r *Responder) Page(w http.ResponseWriter, req *http.Request, items interface{}, token pagetoken.Token, total int, opts ...Option) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		r.Err(w, req, fmt.Errorf("page items must be a slice, got %T", items))
+		return
+	}
+	if v.IsNil() {
+		// respond with an empty list rather than null.
+		items = []struct{}{}
+	}
+
+	next, err := nextToken(v, token, total)
+	if err != nil {
+		r.Err(w, req, err)
+		return
+	}
+
+	resp := PageResponse{
+		Items:         items,
+		NextPageToken: next,
+		PrevPageToken: token.PreviousToken(),
+	}
+	if total != pagetoken.TotalTODO {
+		resp.Total = &total
+	}
+
+	var links []string
+	if resp.NextPageToken != "" {
+		links = append(links, pageLink(req, resp.NextPageToken, "next"))
+	}
+	if resp.PrevPageToken != "" {
+		links = append(links, pageLink(req, resp.PrevPageToken, "prev"))
+	}
+	if len(links) > 0 {
+		opts = append([]Option{Header("Link", strings.Join(links, ", "))}, opts...)
+	}
+
+	r.With(w, req, http.StatusOK, resp, opts...)
+}
+
+var keyerType = reflect.TypeOf((*pagetoken.Keyer)(nil)).Elem()
+
+// nextToken returns the token of the page after the items. Returns an error
+// for keyset tokens if the items do not implement pagetoken.Keyer, rather than
+// ending the list at this page.
+func nextToken(items reflect.Value, token pagetoken.Token, total int) (string, error) {
+	n := items.Len()
+	if token.Keyset == nil {
+		return token.NextToken(n, total), nil
+	}
+	if elem := items.Type().Elem(); elem.Kind() != reflect.Interface && !elem.Implements(keyerType) {
+		return "", fmt.Errorf("keyset page items must implement pagetoken.Keyer, got %s", elem)
+	}
+	if n == 0 {
+		return "", nil
+	}
+	last, ok := items.Index(n - 1).Interface().(pagetoken.Keyer)
+	if !ok {
+		return "", fmt.Errorf("keyset page items must implement pagetoken.Keyer, got %T", items.Index(n-1).Interface())
+	}
+	return token.NextKeysetToken(n, last.KeysetValues(token.Keyset.Order)...), nil
+}
+
+// pageLink returns a link to the request URL with the page token replaced.
+// The offset query param is removed, as it applies when the token's offset is
+// zero.
+func pageLink(req *http.Request, token, rel string) string {
+	u := *req.URL
+	q := u.Query()
+	q.Del("offset")
+	q.Set(pagetoken.TokenQueryParam, token)
+	u.RawQuery = q.Encode()
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+}
@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/graymeta/gmkit/api"
+	"github.com/graymeta/gmkit/logger"
+	"github.com/graymeta/gmkit/pagetoken"
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type asset struct {
+	ID string `json:"id"`
+}
+
+func (a asset) KeysetValues(order []postgres.OrderPair) []interface{} {
+	return []interface{}{a.ID}
+}
+
+func TestPage(t *testing.T) {
+	respond := api.NewResponder(logger.Silence(), "v2")
+
+	page := func(t *testing.T, target string, items interface{}, total int, opts ...pagetoken.TokenOptFn) (*httptest.ResponseRecorder, api.PageResponse) {
+		t.Helper()
+		req := httptest.NewRequest("GET", target, nil)
+		token := pagetoken.GetTokenFromQuery(req.URL.Query(), opts...)
+		w := httptest.NewRecorder()
+		respond.Page(w, req, items, token, total)
+
+		var resp api.PageResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return w, resp
+	}
+
+	t.Run("middle page", func(t *testing.T) {
+		w, resp := page(t, "/assets?q=cats&limit=2&offset=2", []asset{{"c"}, {"d"}}, 10)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []interface{}{map[string]interface{}{"id": "c"}, map[string]interface{}{"id": "d"}}, resp.Items)
+		require.NotNil(t, resp.Total)
+		assert.Equal(t, 10, *resp.Total)
+
+		next := pagetoken.GetToken(resp.NextPageToken)
+		assert.Equal(t, 4, next.Offset)
+		prev := pagetoken.GetToken(resp.PrevPageToken)
+		assert.Equal(t, 0, prev.Offset)
+
+		assert.Equal(t, fmt.Sprintf(`</assets?limit=2&page-token=%s&q=cats>; rel="next", </assets?limit=2&page-token=%s&q=cats>; rel="prev"`,
+			url.QueryEscape(resp.NextPageToken), url.QueryEscape(resp.PrevPageToken)), w.Header().Get("Link"))
+	})
+
+	t.Run("only page", func(t *testing.T) {
+		w, resp := page(t, "/assets?limit=2", []asset(nil), pagetoken.TotalTODO)
+
+		assert.Equal(t, []interface{}{}, resp.Items)
+		assert.Nil(t, resp.Total)
+		assert.Empty(t, resp.NextPageToken)
+		assert.Empty(t, resp.PrevPageToken)
+		assert.Empty(t, w.Header().Get("Link"))
+	})
+
+	t.Run("keyset page", func(t *testing.T) {
+		_, resp := page(t, "/assets?limit=2", []asset{{"a"}, {"b"}}, pagetoken.TotalTODO,
+			pagetoken.KeysetOrder(postgres.OrderPair{Col: "id"}))
+
+		next := pagetoken.GetToken(resp.NextPageToken)
+		require.NotNil(t, next.Keyset)
+		assert.Equal(t, []interface{}{"b"}, next.Keyset.Values)
+		assert.Empty(t, resp.PrevPageToken)
+	})
+
+	t.Run("keyset page items must be keyers", func(t *testing.T) {
+		type plain struct {
+			ID string `json:"id"`
+		}
+		keyset := pagetoken.KeysetOrder(postgres.OrderPair{Col: "id"})
+
+		for _, items := range []interface{}{[]plain{{"a"}}, []plain{}, []interface{}{plain{"a"}}} {
+			req := httptest.NewRequest("GET", "/assets?limit=2", nil)
+			w := httptest.NewRecorder()
+			respond.Page(w, req, items, pagetoken.GetTokenFromQuery(req.URL.Query(), keyset), pagetoken.TotalTODO)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code, "%T", items)
+		}
+
+		_, resp := page(t, "/assets?limit=1", []interface{}{asset{"a"}}, pagetoken.TotalTODO, keyset)
+		assert.NotEmpty(t, resp.NextPageToken)
+	})
+
+	t.Run("items must be a slice", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/assets", nil)
+		w := httptest.NewRecorder()
+		respond.Page(w, req, asset{"a"}, pagetoken.Token{}, 1)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -99,57 +99,31 @@ func newErr(requestID, msg string) ErrorResponse {
 	}
 }
 
-// Err responds with an error that corresponds to the behavior the type is illustrating.
+// Err responds with an error that corresponds to the behavior the type is illustrating,
+// as classified by ClassifyError.
 func (r *Responder) Err(w http.ResponseWriter, req *http.Request, err error) {
 	reqID := middleware.GetReqID(req.Context())
-	logMsg := true
-	defer func() {
+	class := ClassifyError(err)
+	if !class.quiet {
 		sanitizedQuery := middleware.SanitizeQuery(req.URL.Query())
-		if logMsg {
-			msg := err.Error()
-			if ieErr, ok := err.(gmerrors.InternalErrorMessage); ok {
-				msg = ieErr.InternalErrorMessage()
-			}
-			r.log.Err("api_response_error",
-				"method", req.Method,
-				"path", req.URL.Path,
-				"query", sanitizedQuery.Encode(),
-				"err", msg,
-				"http_request_id", reqID,
-			)
+		msg := err.Error()
+		if ieErr, ok := err.(gmerrors.InternalErrorMessage); ok {
+			msg = ieErr.InternalErrorMessage()
 		}
-	}()
-
-	// not using a type switch here as we may have types that satisfy the behavior but
-	// are purposely not a valid error type as they return false, which indicates this.
-	// most useful when receiving a PGErr type that does fulfill more than 1 behavior potentially.
-	if httpErr, ok := err.(gmerrors.HTTP); ok {
-		r.With(w, req, httpErr.StatusCode(), newErr(reqID, err.Error()))
-		return
-	}
-
-	if nfErr, ok := err.(gmerrors.NotFounder); ok && nfErr.NotFound() {
-		logMsg = false
-		r.With(w, req, http.StatusNotFound, newErr(reqID, "resource not found"))
-		return
-	}
-
-	if exErr, ok := err.(gmerrors.Exister); ok && exErr.Exists() {
-		r.With(w, req, http.StatusUnprocessableEntity, newErr(reqID, "resource exists"))
-		return
-	}
-
-	if cfErr, ok := err.(gmerrors.Conflicter); ok && cfErr.Conflict() {
-		r.With(w, req, http.StatusUnprocessableEntity, newErr(reqID, "resource conflict"))
-		return
+		r.log.Err("api_response_error",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"query", sanitizedQuery.Encode(),
+			"err", msg,
+			"http_request_id", reqID,
+		)
 	}
 
-	if tmpErr, ok := err.(gmerrors.Temporarier); ok && tmpErr.Temporary() {
-		r.With(w, req, http.StatusServiceUnavailable, newErr(reqID, "service unavailable"))
-		return
+	msg := class.Message
+	if msg == "" {
+		msg = err.Error()
 	}
-
-	r.With(w, req, http.StatusInternalServerError, newErr(reqID, "internal server error"))
+	r.With(w, req, class.Status, newErr(reqID, msg))
 }
 
 // OK responds with status code OK and JSON response indicating the operation succeeded
@@ -2,9 +2,8 @@ package bulk
 
 import (
 	"errors"
-	"net/http"
 
-	gmerrors "github.com/graymeta/gmkit/errors"
+	"github.com/graymeta/gmkit/api"
 )
 
 // ReadRequest is a request to read multiple IDs of a single resource type.
@@ -30,28 +29,13 @@ func (r ReadRequest) OK() error {
 	return nil
 }
 
-// ClassifyError takes an error and tries to bucket it into one of the error categories.
+// ClassifyError takes an error and tries to bucket it into one of the error categories,
+// in agreement with the status api.Responder.Err responds with for the error.
 func ClassifyError(err error) string {
 	if err == nil {
 		return ""
 	}
-
-	if nf, ok := err.(gmerrors.NotFounder); ok && nf.NotFound() {
-		return ErrorNotFound
-	}
-
-	if hErr, ok := err.(gmerrors.HTTP); ok {
-		switch hErr.StatusCode() {
-		case http.StatusNotFound:
-			return ErrorNotFound
-		case http.StatusForbidden:
-			return ErrorForbidden
-		default:
-			return ErrorInternalServerError
-		}
-	}
-
-	return ErrorInternalServerError
+	return api.ClassifyError(err).Type
 }
 
 // ResponseError captures information about an error for a specific resource.
@@ -60,24 +44,45 @@ type ResponseError struct {
 	Type    string `json:"type"`
 }
 
-// NewResponseError initializes a NewResponseError
+// NewResponseError initializes a NewResponseError. The message is the one
+// api.Responder.Err reports for the error, so that the details of internal
+// errors are not reported to clients.
 func NewResponseError(err error) *ResponseError {
 	if err == nil {
 		return nil
 	}
 
+	class := api.ClassifyError(err)
+	msg := class.Message
+	if msg == "" {
+		msg = err.Error()
+	}
 	return &ResponseError{
-		Message: err.Error(),
-		Type:    ClassifyError(err),
+		Message: msg,
+		Type:    class.Type,
 	}
 }
 
 // Error categories.
 const (
+	// ErrorBadRequest indicates the request for the resource was malformed.
+	ErrorBadRequest = api.ErrorTypeBadRequest
+	// ErrorUnauthorized indicates the user making the request is not authenticated.
+	ErrorUnauthorized = api.ErrorTypeUnauthorized
 	// ErrorNotFound indicates the given resource was not found.
-	ErrorNotFound = "NotFound"
+	ErrorNotFound = api.ErrorTypeNotFound
 	//ErrorForbidden indicates the given resource was not accessible by the user making the request.
-	ErrorForbidden = "Forbidden"
+	ErrorForbidden = api.ErrorTypeForbidden
+	// ErrorExists indicates the given resource already exists.
+	ErrorExists = api.ErrorTypeExists
+	// ErrorConflict indicates the given resource was changed by a conflicting request.
+	ErrorConflict = api.ErrorTypeConflict
+	// ErrorValidationFailed indicates the given resource was not valid.
+	ErrorValidationFailed = api.ErrorTypeValidationFailed
+	// ErrorTooManyRequests indicates the user making the request was rate limited.
+	ErrorTooManyRequests = api.ErrorTypeTooManyRequests
+	// ErrorUnavailable indicates a temporary failure, after which the request may be retried.
+	ErrorUnavailable = api.ErrorTypeUnavailable
 	// ErrorInternalServerError indicates a server side error occured.
-	ErrorInternalServerError = "InternalServerError"
+	ErrorInternalServerError = api.ErrorTypeInternalServerError
 )
@@ -0,0 +1,145 @@
+package bulk
+
+import (
+	"context"
+	"sync"
+)
+
+// ReadResult is the result of reading one ID of a ReadRequest. Exactly one of
+// Data and Error is set.
+type ReadResult[T any] struct {
+	ID    string         `json:"id"`
+	Data  *T             `json:"data,omitempty"`
+	Error *ResponseError `json:"error,omitempty"`
+}
+
+// ReadResponse holds the result of each ID of a ReadRequest, in the order the
+// IDs were first requested.
+type ReadResponse[T any] []ReadResult[T]
+
+// ByID returns the results keyed by ID.
+func (r ReadResponse[T]) ByID() map[string]ReadResult[T] {
+	m := make(map[string]ReadResult[T], len(r))
+	for _, res := range r {
+		m[res.ID] = res
+	}
+	return m
+}
+
+// FetchFn reads a single resource by ID.
+type FetchFn[T any] func(ctx context.Context, id string) (T, error)
+
+// BatchFetchFn reads the resources with the given IDs, such as with a query
+// filtered by postgres.ByIDsIn. IDs that are missing from the returned map are
+// not found.
+type BatchFetchFn[T any] func(ctx context.Context, ids []string) (map[string]T, error)
+
+type missingErr struct{}
+
+func (missingErr) Error() string {
+	return "resource not found"
+}
+
+func (missingErr) NotFound() bool {
+	return true
+}
+
+// Read reads each ID of the request with fetch, running up to concurrency
+// fetches at once. Duplicate IDs are read once. An ID that fails to be read
+// has a ResponseError in its result, and does not fail the other IDs. The
+// request should have been validated with OK, as api.Decode does.
+func Read[T any](ctx context.Context, req ReadRequest, fetch FetchFn[T], concurrency int) ReadResponse[T] {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ids := uniqueIDs(req.IDs)
+	results := make(ReadResponse[T], len(ids))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		results[i].ID = id
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Error = NewResponseError(ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(res *ReadResult[T]) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			data, err := fetch(ctx, res.ID)
+			res.set(data, err)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
+
+// ReadBatch reads the IDs of the request with fetch, in batches of up to
+// batchSize IDs, or in a single batch if batchSize is zero. Duplicate IDs are
+// read once. If a batch fails, each of its IDs has the error in its result,
+// and the other batches are still read.
+func ReadBatch[T any](ctx context.Context, req ReadRequest, fetch BatchFetchFn[T], batchSize int) ReadResponse[T] {
+	ids := uniqueIDs(req.IDs)
+	if batchSize < 1 {
+		batchSize = len(ids)
+	}
+
+	results := make(ReadResponse[T], len(ids))
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var (
+			found map[string]T
+			err   = ctx.Err()
+		)
+		if err == nil {
+			found, err = fetch(ctx, ids[start:end])
+		}
+		for i := start; i < end; i++ {
+			results[i].ID = ids[i]
+			if err != nil {
+				results[i].Error = NewResponseError(err)
+				continue
+			}
+			data, ok := found[ids[i]]
+			if !ok {
+				results[i].Error = NewResponseError(missingErr{})
+				continue
+			}
+			results[i].set(data, nil)
+		}
+	}
+
+	return results
+}
+
+func (r *ReadResult[T]) set(data T, err error) {
+	if err != nil {
+		r.Error = NewResponseError(err)
+		return
+	}
+	r.Data = &data
+}
+
+// uniqueIDs returns the ids without duplicates, in the order they first occur.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,114 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	t.Run("results in request order with partial failures", func(t *testing.T) {
+		var calls int32
+		fetch := func(ctx context.Context, id string) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			switch id {
+			case "missing":
+				return "", notFound{true}
+			case "forbidden":
+				return "", httpErr{http.StatusForbidden}
+			}
+			return "item " + id, nil
+		}
+
+		req := ReadRequest{IDs: []string{"b", "missing", "a", "b", "forbidden"}}
+		res := Read(context.Background(), req, fetch, 2)
+
+		require.Len(t, res, 4)
+		assert.Equal(t, int32(4), calls, "duplicate ids are fetched once")
+
+		for i, id := range []string{"b", "missing", "a", "forbidden"} {
+			assert.Equal(t, id, res[i].ID)
+		}
+		require.NotNil(t, res[0].Data)
+		assert.Equal(t, "item b", *res[0].Data)
+		assert.Nil(t, res[0].Error)
+		assert.Nil(t, res[1].Data)
+		assert.Equal(t, &ResponseError{Message: "resource not found", Type: ErrorNotFound}, res[1].Error)
+		assert.Equal(t, ErrorForbidden, res.ByID()["forbidden"].Error.Type)
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		var running, max int32
+		fetch := func(ctx context.Context, id string) (int, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return 1, nil
+		}
+
+		res := Read(context.Background(), ReadRequest{IDs: []string{"1", "2", "3", "4", "5", "6", "7"}}, fetch, 3)
+		require.Len(t, res, 7)
+		assert.Equal(t, int32(3), max)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		fetch := func(ctx context.Context, id string) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		res := Read(ctx, ReadRequest{IDs: []string{"1", "2", "3"}}, fetch, 1)
+		require.Len(t, res, 3)
+		for _, r := range res {
+			require.NotNil(t, r.Error)
+			assert.Equal(t, ErrorInternalServerError, r.Error.Type)
+		}
+	})
+}
+
+func TestReadBatch(t *testing.T) {
+	var batches [][]string
+	fetch := func(ctx context.Context, ids []string) (map[string]string, error) {
+		batches = append(batches, ids)
+		if ids[0] == "fail" {
+			return nil, errors.New("query failed")
+		}
+		found := make(map[string]string)
+		for _, id := range ids {
+			if id != "missing" {
+				found[id] = "item " + id
+			}
+		}
+		return found, nil
+	}
+
+	req := ReadRequest{IDs: []string{"a", "missing", "a", "b", "fail", "c"}}
+	res := ReadBatch(context.Background(), req, fetch, 3)
+
+	assert.Equal(t, [][]string{{"a", "missing", "b"}, {"fail", "c"}}, batches)
+	require.Len(t, res, 5)
+	assert.Equal(t, "item a", *res[0].Data)
+	assert.Equal(t, ErrorNotFound, res[1].Error.Type)
+	assert.Equal(t, "item b", *res[2].Data)
+	assert.Equal(t, &ResponseError{Message: "internal server error", Type: ErrorInternalServerError}, res[3].Error)
+	assert.Equal(t, ErrorInternalServerError, res[4].Error.Type)
+
+	batches = nil
+	res = ReadBatch(context.Background(), ReadRequest{IDs: []string{"a", "b"}}, fetch, 0)
+	assert.Equal(t, [][]string{{"a", "b"}}, batches)
+	assert.Len(t, res, 2)
+}
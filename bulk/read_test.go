@@ -1,10 +1,17 @@
 package bulk
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/graymeta/gmkit/api"
+	"github.com/graymeta/gmkit/logger"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,13 +34,19 @@ func TestReadRequest(t *testing.T) {
 }
 
 func TestResponseError(t *testing.T) {
+	respond := api.NewResponder(logger.Silence(), "v2")
+
 	tests := []struct {
 		description string
 		err         error
 		expected    *ResponseError
 	}{
 		{"nil error", nil, nil},
-		{"not found", notFound{true}, &ResponseError{Message: "not found", Type: ErrorNotFound}},
+		{"not found", notFound{true}, &ResponseError{Message: "resource not found", Type: ErrorNotFound}},
+		{"internal error", errors.New(`pq: relation "secrets" does not exist`), &ResponseError{Message: "internal server error", Type: ErrorInternalServerError}},
+		{"http error", httpErr{http.StatusForbidden}, &ResponseError{Message: "403", Type: ErrorForbidden}},
+		{"wrapped http error", fmt.Errorf("upstream: %w", httpErr{http.StatusForbidden}), &ResponseError{Message: "forbidden", Type: ErrorForbidden}},
+		{"validation failed", decodeErr(t, `{}`), &ResponseError{Message: decodeErr(t, `{}`).Error(), Type: ErrorValidationFailed}},
 	}
 
 	for _, tt := range tests {
@@ -46,6 +59,13 @@ func TestResponseError(t *testing.T) {
 
 			require.Equal(t, tt.expected.Message, res.Message)
 			require.Equal(t, tt.expected.Type, res.Type)
+
+			// the message agrees with the one Err responds with.
+			w := httptest.NewRecorder()
+			respond.Err(w, httptest.NewRequest("GET", "/", nil), tt.err)
+			var errResp api.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+			require.Equal(t, errResp.Error.Message, res.Message)
 		})
 	}
 }
@@ -62,6 +82,21 @@ func TestClassifyError(t *testing.T) {
 		{"http error - 404", httpErr{http.StatusNotFound}, ErrorNotFound},
 		{"http error - 403", httpErr{http.StatusForbidden}, ErrorForbidden},
 		{"http error - 500", httpErr{http.StatusInternalServerError}, ErrorInternalServerError},
+		{"http error - 400", httpErr{http.StatusBadRequest}, ErrorBadRequest},
+		{"http error - 401", httpErr{http.StatusUnauthorized}, ErrorUnauthorized},
+		{"http error - 409", httpErr{http.StatusConflict}, ErrorConflict},
+		{"http error - 422", httpErr{http.StatusUnprocessableEntity}, ErrorValidationFailed},
+		{"http error - 429", httpErr{http.StatusTooManyRequests}, ErrorTooManyRequests},
+		{"http error - 503", httpErr{http.StatusServiceUnavailable}, ErrorUnavailable},
+		{"exists", behavior{exists: true}, ErrorExists},
+		{"conflict", behavior{conflict: true}, ErrorConflict},
+		{"temporary", behavior{temporary: true}, ErrorUnavailable},
+		{"retry", behavior{retry: true}, ErrorUnavailable},
+		{"no behavior", behavior{}, ErrorInternalServerError},
+		{"validation failed", decodeErr(t, `{}`), ErrorValidationFailed},
+		{"invalid json", decodeErr(t, `{`), ErrorBadRequest},
+		{"wrapped not found", fmt.Errorf("reading: %w", notFound{true}), ErrorNotFound},
+		{"wrapped http error", fmt.Errorf("reading: %w", httpErr{http.StatusForbidden}), ErrorForbidden},
 	}
 
 	for _, tt := range tests {
@@ -83,6 +118,24 @@ func (e notFound) NotFound() bool {
 	return e.notFound
 }
 
+type behavior struct {
+	exists, conflict, temporary, retry bool
+}
+
+func (e behavior) Error() string   { return "behavior" }
+func (e behavior) Exists() bool    { return e.exists }
+func (e behavior) Conflict() bool  { return e.conflict }
+func (e behavior) Temporary() bool { return e.temporary }
+func (e behavior) Retry() bool     { return e.retry }
+
+// decodeErr returns the error of api.Decode decoding body into a widget.
+func decodeErr(t *testing.T, body string) error {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	err := api.Decode(req, &widget{})
+	require.Error(t, err)
+	return err
+}
+
 type httpErr struct {
 	code int
 }
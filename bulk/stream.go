@@ -0,0 +1,282 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ContentTypeNDJSON is the content type of newline delimited JSON, in which each
+// line of the body is a JSON document.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// Stream defaults.
+const (
+	DefaultMaxLineSize   = 1 << 20
+	DefaultFlushInterval = time.Second
+)
+
+// ErrLineTooLong is the error of a LineError for a line longer than the max
+// line size of a StreamReader.
+var ErrLineTooLong = errors.New("line too long")
+
+var errStreamClosed = errors.New("stream closed")
+
+// LineError is an error in one line of an NDJSON stream.
+type LineError struct {
+	// Line is the number of the line, counting from 1.
+	Line int
+	Err  error
+
+	status int
+}
+
+// Error returns the error with its line number.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the error of the line.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the http status code of a line that could not be decoded
+// or that failed validation.
+func (e *LineError) StatusCode() int {
+	return e.status
+}
+
+// StreamOptFn is a functional option for configuring a StreamReader or
+// StreamWriter.
+type StreamOptFn func(o *streamOpts)
+
+type streamOpts struct {
+	maxLineSize   int
+	flushEvery    int
+	flushInterval time.Duration
+}
+
+// MaxLineSize sets the size in bytes of the longest line a StreamReader reads,
+// not including the newline. Defaults to DefaultMaxLineSize.
+func MaxLineSize(n int) StreamOptFn {
+	return func(o *streamOpts) {
+		o.maxLineSize = n
+	}
+}
+
+// FlushEvery flushes a StreamWriter to the client after every n items. Zero,
+// the default, only flushes on the flush interval.
+func FlushEvery(n int) StreamOptFn {
+	return func(o *streamOpts) {
+		o.flushEvery = n
+	}
+}
+
+// FlushInterval sets the longest a StreamWriter holds written items before
+// flushing them to the client. Zero only flushes on FlushEvery and Close.
+// Defaults to DefaultFlushInterval.
+func FlushInterval(d time.Duration) StreamOptFn {
+	return func(o *streamOpts) {
+		o.flushInterval = d
+	}
+}
+
+func newStreamOpts(opts ...StreamOptFn) streamOpts {
+	o := streamOpts{
+		maxLineSize:   DefaultMaxLineSize,
+		flushInterval: DefaultFlushInterval,
+	}
+	for _, fn := range opts {
+		fn(&o)
+	}
+	return o
+}
+
+// StreamReader reads items of type T from an NDJSON body, one per line, so
+// that large imports need not be held in memory or split into many requests.
+// Blank lines are skipped. A line that fails to decode, or whose item fails
+// validation with its OK method, is reported by Item without stopping the
+// stream, so that each line can succeed or fail independently:
+//
+//	sr := bulk.NewStreamReader[widget](req.Body)
+//	for sr.Next() {
+//		item, err := sr.Item()
+//		...
+//	}
+//	if err := sr.Err(); err != nil {
+//		...
+//	}
+type StreamReader[T any] struct {
+	scanner *bufio.Scanner
+	line    int
+	item    T
+	itemErr error
+	err     error
+}
+
+// NewStreamReader initializes a StreamReader reading from r.
+func NewStreamReader[T any](r io.Reader, opts ...StreamOptFn) *StreamReader[T] {
+	o := newStreamOpts(opts...)
+
+	// the buffer holds the newline as well as the line.
+	max := o.maxLineSize + 1
+	initial := 4096
+	if initial > max {
+		initial = max
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, initial), max)
+	return &StreamReader[T]{scanner: scanner}
+}
+
+// Next advances to the next item, returning false at the end of the stream
+// or when the stream cannot be read further, as reported by Err.
+func (s *StreamReader[T]) Next() bool {
+	for s.err == nil && s.scanner.Scan() {
+		s.line++
+		b := bytes.TrimSpace(s.scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		s.decode(b)
+		return true
+	}
+	if s.err == nil {
+		s.err = s.scanner.Err()
+		if errors.Is(s.err, bufio.ErrTooLong) {
+			s.err = &LineError{Line: s.line + 1, Err: ErrLineTooLong, status: http.StatusBadRequest}
+		}
+	}
+	return false
+}
+
+func (s *StreamReader[T]) decode(b []byte) {
+	var item T
+	s.item, s.itemErr = item, nil
+	if err := json.Unmarshal(b, &item); err != nil {
+		s.itemErr = &LineError{Line: s.line, Err: err, status: http.StatusBadRequest}
+		return
+	}
+	if v, ok := interface{}(&item).(validator); ok {
+		if err := v.OK(); err != nil {
+			s.itemErr = &LineError{Line: s.line, Err: err, status: http.StatusUnprocessableEntity}
+			return
+		}
+	}
+	s.item = item
+}
+
+// Item returns the current item, or a LineError if its line could not be
+// decoded or failed validation.
+func (s *StreamReader[T]) Item() (T, error) {
+	return s.item, s.itemErr
+}
+
+// Line returns the line number of the current item, counting from 1.
+func (s *StreamReader[T]) Line() int {
+	return s.line
+}
+
+// Err returns the error that stopped the stream, if any: a LineError wrapping
+// ErrLineTooLong for a line longer than the max line size, or the error
+// reading the body.
+func (s *StreamReader[T]) Err() error {
+	return s.err
+}
+
+// StreamWriter writes items of type T to an NDJSON response, one per line.
+// Written items are flushed to the client periodically, so that clients can
+// process a large export as it is written. It flushes through
+// middleware.Compress, which compresses each flushed chunk. Close must be
+// called before the handler returns.
+type StreamWriter[T any] struct {
+	mu      sync.Mutex
+	flusher http.Flusher
+	enc     *json.Encoder
+	opts    streamOpts
+	pending int
+	timer   *time.Timer
+	closed  bool
+}
+
+// NewStreamWriter initializes a StreamWriter writing to w, and sets the
+// Content-Type of the response.
+func NewStreamWriter[T any](w http.ResponseWriter, opts ...StreamOptFn) *StreamWriter[T] {
+	w.Header().Set("Content-Type", ContentTypeNDJSON)
+	flusher, _ := w.(http.Flusher)
+	return &StreamWriter[T]{
+		flusher: flusher,
+		enc:     json.NewEncoder(w),
+		opts:    newStreamOpts(opts...),
+	}
+}
+
+// Write writes the item as a line of the response.
+func (s *StreamWriter[T]) Write(item T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errStreamClosed
+	}
+	if err := s.enc.Encode(item); err != nil {
+		return err
+	}
+
+	s.pending++
+	if s.opts.flushEvery > 0 && s.pending >= s.opts.flushEvery {
+		s.flush()
+		return nil
+	}
+	if s.timer == nil && s.opts.flushInterval > 0 {
+		s.timer = time.AfterFunc(s.opts.flushInterval, s.Flush)
+	}
+	return nil
+}
+
+// Flush flushes the written items to the client.
+func (s *StreamWriter[T]) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.flush()
+}
+
+func (s *StreamWriter[T]) flush() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.pending == 0 {
+		return
+	}
+	s.pending = 0
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+// Close flushes the written items, and stops periodic flushing. The response
+// writer must not be used by the StreamWriter once the handler returns, so
+// Close must be called before then.
+func (s *StreamWriter[T]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.flush()
+	s.closed = true
+	return nil
+}
@@ -0,0 +1,142 @@
+package bulk
+
+import (
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/http/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamReader(t *testing.T) {
+	body := strings.Join([]string{
+		`{"name":"a"}`,
+		``,
+		`{"name":`,
+		`{"name":""}`,
+		`  {"name":"b"}  `,
+	}, "\n")
+
+	type line struct {
+		line int
+		name string
+		err  string
+		typ  string
+	}
+	var lines []line
+	sr := NewStreamReader[widget](strings.NewReader(body))
+	for sr.Next() {
+		item, err := sr.Item()
+		l := line{line: sr.Line(), name: item.Name}
+		if err != nil {
+			l.err, l.typ = err.Error(), ClassifyError(err)
+		}
+		lines = append(lines, l)
+	}
+	require.NoError(t, sr.Err())
+
+	assert.Equal(t, []line{
+		{line: 1, name: "a"},
+		{line: 3, err: "line 3: unexpected end of JSON input", typ: ErrorBadRequest},
+		{line: 4, err: "line 4: name must be specified", typ: ErrorValidationFailed},
+		{line: 5, name: "b"},
+	}, lines)
+}
+
+func TestStreamReaderMaxLineSize(t *testing.T) {
+	body := `{"name":"a"}` + "\n" + `{"name":"abc"}` + "\n" + `{"name":"b"}`
+
+	sr := NewStreamReader[widget](strings.NewReader(body), MaxLineSize(len(`{"name":"a"}`)))
+	require.True(t, sr.Next())
+	item, err := sr.Item()
+	require.NoError(t, err)
+	assert.Equal(t, "a", item.Name)
+
+	require.False(t, sr.Next())
+	require.False(t, sr.Next())
+	var lineErr *LineError
+	require.True(t, errors.As(sr.Err(), &lineErr))
+	assert.Equal(t, 2, lineErr.Line)
+	assert.True(t, errors.Is(sr.Err(), ErrLineTooLong))
+	assert.Equal(t, ErrorBadRequest, ClassifyError(sr.Err()))
+}
+
+func TestStreamWriter(t *testing.T) {
+	t.Run("flush every", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		sw := NewStreamWriter[widget](rec, FlushEvery(2), FlushInterval(0))
+		require.NoError(t, sw.Write(widget{Name: "a"}))
+		assert.False(t, rec.Flushed)
+		require.NoError(t, sw.Write(widget{Name: "b"}))
+		assert.True(t, rec.Flushed)
+		require.NoError(t, sw.Close())
+
+		assert.Equal(t, ContentTypeNDJSON, rec.Header().Get("Content-Type"))
+		assert.Equal(t, "{\"name\":\"a\"}\n{\"name\":\"b\"}\n", rec.Body.String())
+		assert.Equal(t, errStreamClosed, sw.Write(widget{Name: "c"}))
+	})
+
+	t.Run("flush on close", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		sw := NewStreamWriter[widget](rec, FlushInterval(0))
+		require.NoError(t, sw.Write(widget{Name: "a"}))
+		assert.False(t, rec.Flushed)
+		require.NoError(t, sw.Close())
+		assert.True(t, rec.Flushed)
+	})
+}
+
+func TestStreamWriterFlushInterval(t *testing.T) {
+	written := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := NewStreamWriter[widget](w, FlushInterval(10*time.Millisecond))
+		defer sw.Close()
+		assert.NoError(t, sw.Write(widget{Name: "a"}))
+		<-written
+		assert.NoError(t, sw.Write(widget{Name: "b"}))
+	})
+	srv := httptest.NewServer(middleware.Compress(handler))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, ContentTypeNDJSON, res.Header.Get("Content-Type"))
+
+	gr, err := gzip.NewReader(res.Body)
+	require.NoError(t, err)
+	sr := NewStreamReader[widget](gr)
+
+	// the first item must be flushed through the gzip writer before the
+	// handler writes the second.
+	read := make(chan []string, 1)
+	go func() {
+		var names []string
+		for sr.Next() {
+			item, _ := sr.Item()
+			names = append(names, item.Name)
+			if len(names) == 1 {
+				close(written)
+			}
+		}
+		read <- names
+	}()
+	select {
+	case names := <-read:
+		require.NoError(t, sr.Err())
+		assert.Equal(t, []string{"a", "b"}, names)
+	case <-time.After(time.Second):
+		close(written)
+		t.Fatal("first item was not flushed")
+	}
+}
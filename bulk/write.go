@@ -0,0 +1,195 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Op is the operation a WriteItem performs.
+type Op string
+
+// Write operations.
+const (
+	OpCreate Op = "create"
+	OpUpdate Op = "update"
+	OpUpsert Op = "upsert"
+	OpDelete Op = "delete"
+)
+
+func (o Op) valid() bool {
+	switch o {
+	case OpCreate, OpUpdate, OpUpsert, OpDelete:
+		return true
+	}
+	return false
+}
+
+// WriteItem is an operation on one resource, identified by a client supplied
+// ID. Data is required by every operation but OpDelete.
+type WriteItem[T any] struct {
+	ID   string `json:"id"`
+	Op   Op     `json:"op"`
+	Data *T     `json:"data,omitempty"`
+}
+
+// WriteRequest is a request to write multiple resources of a single type.
+type WriteRequest[T any] struct {
+	Items []WriteItem[T] `json:"items"`
+}
+
+var errItemsNotSpecified = errors.New("one or more items must be specified")
+var errDuplicateIDs = errors.New("duplicate id(s) specified")
+
+// validator is the OK convention of api.Decode.
+type validator interface {
+	OK() error
+}
+
+// OK validates the request, and the data of each item that implements an OK
+// method.
+func (r WriteRequest[T]) OK() error {
+	if len(r.Items) == 0 {
+		return errItemsNotSpecified
+	}
+
+	seen := make(map[string]bool, len(r.Items))
+	for i, item := range r.Items {
+		if item.ID == "" {
+			return errBlankIDs
+		}
+		if seen[item.ID] {
+			return errDuplicateIDs
+		}
+		seen[item.ID] = true
+
+		if !item.Op.valid() {
+			return fmt.Errorf("item %d (%s): invalid op %q", i, item.ID, item.Op)
+		}
+		if item.Op == OpDelete {
+			continue
+		}
+		if item.Data == nil {
+			return fmt.Errorf("item %d (%s): data must be specified for %s", i, item.ID, item.Op)
+		}
+		if v, ok := interface{}(item.Data).(validator); ok {
+			if err := v.OK(); err != nil {
+				return fmt.Errorf("item %d (%s): %w", i, item.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Status is the outcome of a WriteItem.
+type Status string
+
+// Write statuses.
+const (
+	// StatusSucceeded indicates the item was written.
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed indicates the item failed to be written, as described by
+	// the error of its result.
+	StatusFailed Status = "failed"
+	// StatusAborted indicates the item was not written because another item
+	// of an all-or-nothing write failed.
+	StatusAborted Status = "aborted"
+)
+
+// WriteResult is the result of one WriteItem.
+type WriteResult struct {
+	ID     string         `json:"id"`
+	Op     Op             `json:"op"`
+	Status Status         `json:"status"`
+	Error  *ResponseError `json:"error,omitempty"`
+}
+
+// WriteResponse holds the result of each item of a WriteRequest, in the order
+// of the request.
+type WriteResponse struct {
+	Results []WriteResult `json:"results"`
+}
+
+// Failed reports whether any item was not written.
+func (r WriteResponse) Failed() bool {
+	for _, res := range r.Results {
+		if res.Status != StatusSucceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteFn writes one item with db.
+type WriteFn[T any] func(ctx context.Context, db postgres.CRUD, item WriteItem[T]) error
+
+// WriteAll writes every item of the request with write in a single
+// transaction, which is committed only if every item is written. The first
+// item to fail stops the write, and the other items are aborted. An error is
+// returned only if the transaction could not be started or committed; item
+// failures are reported in the response. Resource names the resource in
+// database errors, as with postgres.Commit. The request should have been
+// validated with OK, as api.Decode does.
+func WriteAll[T any](ctx context.Context, db *sqlx.DB, resource string, req WriteRequest[T], write WriteFn[T]) (WriteResponse, error) {
+	resp := newWriteResponse(req)
+
+	tx, err := postgres.NewTx(ctx, db)
+	if err != nil {
+		return WriteResponse{}, postgres.Err(resource, err)
+	}
+
+	for i, item := range req.Items {
+		if err := write(ctx, tx, item); err != nil {
+			for j := range resp.Results {
+				resp.Results[j].Status = StatusAborted
+			}
+			resp.Results[i].Status = StatusFailed
+			resp.Results[i].Error = NewResponseError(err)
+			if err := tx.Rollback(); err != nil {
+				return resp, postgres.Err(resource, err)
+			}
+			return resp, nil
+		}
+	}
+
+	if err := postgres.Commit(tx, resource); err != nil {
+		return WriteResponse{}, err
+	}
+	for i := range resp.Results {
+		resp.Results[i].Status = StatusSucceeded
+	}
+	return resp, nil
+}
+
+// WriteEach writes each item of the request with write, independently of the
+// other items. An item that fails to be written has a ResponseError in its
+// result, and does not fail the other items. The request should have been
+// validated with OK, as api.Decode does.
+func WriteEach[T any](ctx context.Context, db postgres.CRUD, req WriteRequest[T], write WriteFn[T]) WriteResponse {
+	resp := newWriteResponse(req)
+	for i, item := range req.Items {
+		err := ctx.Err()
+		if err == nil {
+			err = write(ctx, db, item)
+		}
+		if err != nil {
+			resp.Results[i].Status = StatusFailed
+			resp.Results[i].Error = NewResponseError(err)
+			continue
+		}
+		resp.Results[i].Status = StatusSucceeded
+	}
+	return resp
+}
+
+func newWriteResponse[T any](req WriteRequest[T]) WriteResponse {
+	resp := WriteResponse{Results: make([]WriteResult, len(req.Items))}
+	for i, item := range req.Items {
+		resp.Results[i] = WriteResult{ID: item.ID, Op: item.Op}
+	}
+	return resp
+}
@@ -0,0 +1,183 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type widget struct {
+	Name string `json:"name"`
+}
+
+func (w widget) OK() error {
+	if w.Name == "" {
+		return errors.New("name must be specified")
+	}
+	return nil
+}
+
+func TestWriteRequest(t *testing.T) {
+	w := &widget{Name: "a"}
+	tests := []struct {
+		description string
+		items       []WriteItem[widget]
+		expected    string
+	}{
+		{"normal", []WriteItem[widget]{{ID: "1", Op: OpCreate, Data: w}, {ID: "2", Op: OpDelete}}, ""},
+		{"no items", nil, errItemsNotSpecified.Error()},
+		{"blank id", []WriteItem[widget]{{Op: OpDelete}}, errBlankIDs.Error()},
+		{"duplicate ids", []WriteItem[widget]{{ID: "1", Op: OpDelete}, {ID: "1", Op: OpDelete}}, errDuplicateIDs.Error()},
+		{"invalid op", []WriteItem[widget]{{ID: "1", Op: "merge", Data: w}}, `item 0 (1): invalid op "merge"`},
+		{"missing data", []WriteItem[widget]{{ID: "1", Op: OpDelete}, {ID: "2", Op: OpUpsert}}, "item 1 (2): data must be specified for upsert"},
+		{"invalid data", []WriteItem[widget]{{ID: "1", Op: OpUpdate, Data: &widget{}}}, "item 0 (1): name must be specified"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			err := WriteRequest[widget]{Items: tt.items}.OK()
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestWriteAll(t *testing.T) {
+	req := WriteRequest[widget]{Items: []WriteItem[widget]{
+		{ID: "1", Op: OpCreate, Data: &widget{Name: "a"}},
+		{ID: "2", Op: OpUpdate, Data: &widget{Name: "b"}},
+		{ID: "3", Op: OpDelete},
+	}}
+
+	t.Run("commits when every item succeeds", func(t *testing.T) {
+		db, d := newFakeDB(t)
+		resp, err := WriteAll(context.Background(), db, "widgets", req, execWidget(""))
+		require.NoError(t, err)
+
+		assert.False(t, resp.Failed())
+		assert.Equal(t, []WriteResult{
+			{ID: "1", Op: OpCreate, Status: StatusSucceeded},
+			{ID: "2", Op: OpUpdate, Status: StatusSucceeded},
+			{ID: "3", Op: OpDelete, Status: StatusSucceeded},
+		}, resp.Results)
+		assert.Equal(t, []string{"begin", "create 1", "update 2", "delete 3", "commit"}, d.log())
+	})
+
+	t.Run("rolls back when an item fails", func(t *testing.T) {
+		db, d := newFakeDB(t)
+		resp, err := WriteAll(context.Background(), db, "widgets", req, execWidget("2"))
+		require.NoError(t, err)
+
+		assert.True(t, resp.Failed())
+		assert.Equal(t, []WriteResult{
+			{ID: "1", Op: OpCreate, Status: StatusAborted},
+			{ID: "2", Op: OpUpdate, Status: StatusFailed, Error: &ResponseError{Message: "resource not found", Type: ErrorNotFound}},
+			{ID: "3", Op: OpDelete, Status: StatusAborted},
+		}, resp.Results)
+		assert.Equal(t, []string{"begin", "create 1", "rollback"}, d.log())
+	})
+}
+
+func TestWriteEach(t *testing.T) {
+	req := WriteRequest[widget]{Items: []WriteItem[widget]{
+		{ID: "1", Op: OpCreate, Data: &widget{Name: "a"}},
+		{ID: "2", Op: OpUpdate, Data: &widget{Name: "b"}},
+		{ID: "3", Op: OpDelete},
+	}}
+
+	db, d := newFakeDB(t)
+	resp := WriteEach(context.Background(), db, req, execWidget("2"))
+
+	assert.True(t, resp.Failed())
+	assert.Equal(t, []WriteResult{
+		{ID: "1", Op: OpCreate, Status: StatusSucceeded},
+		{ID: "2", Op: OpUpdate, Status: StatusFailed, Error: &ResponseError{Message: "resource not found", Type: ErrorNotFound}},
+		{ID: "3", Op: OpDelete, Status: StatusSucceeded},
+	}, resp.Results)
+	assert.Equal(t, []string{"create 1", "delete 3"}, d.log())
+}
+
+// execWidget returns a WriteFn that executes a statement naming the op and id
+// of each item, and fails the item with the id failID.
+func execWidget(failID string) WriteFn[widget] {
+	return func(ctx context.Context, db postgres.CRUD, item WriteItem[widget]) error {
+		if item.ID == failID {
+			return missingErr{}
+		}
+		_, err := db.ExecContext(ctx, string(item.Op)+" "+item.ID)
+		return err
+	}
+}
+
+// fakeDriver is a database/sql driver that logs the statements executed and
+// transactions begun, committed and rolled back.
+type fakeDriver struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func newFakeDB(t *testing.T) (*sqlx.DB, *fakeDriver) {
+	d := &fakeDriver{}
+	db := sqlx.NewDb(sql.OpenDB(d), "postgres")
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func (d *fakeDriver) log() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.entries
+}
+
+func (d *fakeDriver) record(entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = append(d.entries, entry)
+}
+
+func (d *fakeDriver) Connect(context.Context) (driver.Conn, error) { return fakeConn{d}, nil }
+func (d *fakeDriver) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c fakeConn) Close() error { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	c.d.record("begin")
+	return fakeTx(c), nil
+}
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx fakeConn
+
+func (tx fakeTx) Commit() error {
+	tx.d.record("commit")
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.d.record("rollback")
+	return nil
+}
@@ -3,6 +3,7 @@ package httpc
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/graymeta/gmkit/backoff"
 )
@@ -23,6 +24,9 @@ type Client struct {
 	authFn      AuthFn
 	backoff     backoff.Backoffer
 	seekParams  *seekParams
+
+	maxResponseBytes int64
+	readTimeout      time.Duration
 }
 
 // New returns a new client.
@@ -86,5 +90,8 @@ func (c *Client) Req(method, addr string) *Request {
 		backoff:       c.backoff,
 		responseErrFn: c.respRetryFn,
 		seekParams:    c.seekParams,
+
+		maxResponseBytes: c.maxResponseBytes,
+		readTimeout:      c.readTimeout,
 	}
 }
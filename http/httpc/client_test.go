@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	stderrors "errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -1193,3 +1194,171 @@ func drain(resp *http.Response) {
 		resp.Body.Close()
 	}
 }
+
+func TestRequest_Stream(t *testing.T) {
+	t.Run("streams body", func(t *testing.T) {
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoReturns(stubRespNBody(t, http.StatusOK, foo{Name: "name"}), nil)
+
+		client := httpc.New(doer)
+
+		var actual foo
+		err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			Stream(context.TODO(), httpc.JSONDecode(&actual))
+		require.NoError(t, err)
+		assert.Equal(t, "name", actual.Name)
+	})
+
+	t.Run("stream errors are not retried", func(t *testing.T) {
+		boffer := backoff.New(
+			backoff.InitBackoff(time.Nanosecond),
+			backoff.MaxBackoff(time.Nanosecond),
+			backoff.MaxCalls(3),
+		)
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoStub = func(r *http.Request) (*http.Response, error) {
+			return stubResp(http.StatusOK), nil
+		}
+
+		client := httpc.New(doer, httpc.WithBackoff(boffer))
+
+		err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			Stream(context.TODO(), func(io.Reader) error {
+				return &fakeRetryErr{stderrors.New("stream failed")}
+			})
+		require.Error(t, err)
+		assert.Equal(t, 1, doer.DoCallCount())
+	})
+}
+
+func TestRequest_MaxResponseBytes(t *testing.T) {
+	body := strings.Repeat("a", 10)
+
+	tests := []struct {
+		name  string
+		limit int64
+		err   error
+	}{
+		{"no limit", 0, nil},
+		{"under limit", 11, nil},
+		{"at limit", 10, nil},
+		{"over limit", 9, httpc.ErrResponseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newDoer := func() *httpcfakes.FakeDoer {
+				doer := new(httpcfakes.FakeDoer)
+				doer.DoStub = func(r *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(strings.NewReader(body)),
+					}, nil
+				}
+				return doer
+			}
+
+			readAll := func(r io.Reader) error {
+				_, err := ioutil.ReadAll(r)
+				return err
+			}
+
+			t.Run("Do", func(t *testing.T) {
+				err := httpc.New(newDoer(), httpc.WithMaxResponseBytes(tt.limit)).
+					GET("/foo").
+					Success(httpc.StatusOK()).
+					Decode(readAll).
+					Do(context.TODO())
+				assert.Equal(t, tt.err, stderrors.Unwrap(err))
+			})
+
+			t.Run("DoAndGetReader", func(t *testing.T) {
+				resp, err := httpc.New(newDoer()).
+					GET("/foo").
+					Success(httpc.StatusOK()).
+					MaxResponseBytes(tt.limit).
+					DoAndGetReader(context.TODO())
+				require.NoError(t, err)
+				defer drain(resp)
+
+				assert.Equal(t, tt.err, readAll(resp.Body))
+			})
+
+			t.Run("Stream", func(t *testing.T) {
+				err := httpc.New(newDoer()).
+					GET("/foo").
+					Success(httpc.StatusOK()).
+					MaxResponseBytes(tt.limit).
+					Stream(context.TODO(), readAll)
+				assert.Equal(t, tt.err, stderrors.Unwrap(err))
+			})
+		})
+	}
+}
+
+func TestRequest_ReadTimeout(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer svr.Close()
+
+	client := httpc.New(http.DefaultClient, httpc.WithBaseURL(svr.URL), httpc.WithReadTimeout(10*time.Millisecond))
+
+	readAll := func(r io.Reader) error {
+		_, err := ioutil.ReadAll(r)
+		return err
+	}
+
+	t.Run("Do", func(t *testing.T) {
+		err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			Decode(readAll).
+			Do(context.TODO())
+		assert.Equal(t, httpc.ErrReadTimeout, stderrors.Unwrap(err))
+	})
+
+	t.Run("DoAndGetReader", func(t *testing.T) {
+		resp, err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			DoAndGetReader(context.TODO())
+		require.NoError(t, err)
+		defer drain(resp)
+
+		assert.Equal(t, httpc.ErrReadTimeout, readAll(resp.Body))
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			Stream(context.TODO(), readAll)
+		assert.Equal(t, httpc.ErrReadTimeout, stderrors.Unwrap(err))
+	})
+}
+
+func TestRequest_SeekError(t *testing.T) {
+	seeker := new(httpcfakes.FakeReadSeeker)
+	seeker.SeekReturns(0, stderrors.New("seek failed"))
+
+	doer := new(httpcfakes.FakeDoer)
+	doer.DoReturns(stubResp(http.StatusOK), nil)
+
+	err := httpc.New(doer, httpc.WithResetSeekerToZero()).
+		PUT("/foo").
+		Body(seeker).
+		Success(httpc.StatusOK()).
+		Do(context.TODO())
+	require.Error(t, err)
+	assert.Zero(t, doer.DoCallCount())
+}
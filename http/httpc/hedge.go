@@ -0,0 +1,129 @@
+package httpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+type hedgeParams struct {
+	after time.Duration
+	max   int
+}
+
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+// send sends the request with the doer, hedging the request when the request
+// has been configured to do so.
+func (r *Request) send(req *http.Request) (*http.Response, error) {
+	if !r.hedgeable(req) {
+		return r.doer.Do(req)
+	}
+
+	ctx := req.Context()
+	results := make(chan hedgeResult, r.hedge.max)
+	var cancels []context.CancelFunc
+	launch := func() {
+		hedgeCtx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := r.doer.Do(req.Clone(hedgeCtx))
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+
+	timer := time.NewTimer(r.hedge.after)
+	defer timer.Stop()
+
+	launch()
+	pending := 1
+	var last *hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) < r.hedge.max {
+				launch()
+				pending++
+				timer.Reset(r.hedge.after)
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil && statusMatches(res.resp.StatusCode, r.successFns) {
+				for i, cancel := range cancels {
+					if i != res.index {
+						cancel()
+					}
+				}
+				go discardHedges(results, pending)
+				return withCancelBody(res.resp, cancels[res.index]), nil
+			}
+
+			if last != nil {
+				discardHedge(*last, cancels[last.index])
+			}
+			last = &res
+			if pending > 0 {
+				continue
+			}
+			if last.resp == nil {
+				cancels[last.index]()
+				return nil, last.err
+			}
+			return withCancelBody(last.resp, cancels[last.index]), last.err
+		}
+	}
+}
+
+func (r *Request) hedgeable(req *http.Request) bool {
+	if r.hedge == nil || r.hedge.max < 2 || r.hedge.after <= 0 {
+		return false
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+// discardHedges drains the responses of the hedged requests that lost the race.
+// The contexts of those requests have already been canceled.
+func discardHedges(results <-chan hedgeResult, pending int) {
+	for ; pending > 0; pending-- {
+		res := <-results
+		if res.resp != nil && res.resp.Body != nil {
+			drain(res.resp.Body)
+		}
+	}
+}
+
+func discardHedge(res hedgeResult, cancel context.CancelFunc) {
+	if res.resp != nil && res.resp.Body != nil {
+		drain(res.resp.Body)
+	}
+	cancel()
+}
+
+// cancelBody cancels the context of a hedged request once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func withCancelBody(resp *http.Response, cancel context.CancelFunc) *http.Response {
+	if resp.Body == nil {
+		cancel()
+		return resp
+	}
+	resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp
+}
@@ -0,0 +1,131 @@
+package httpc_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/graymeta/gmkit/backoff"
+	"github.com/graymeta/gmkit/http/httpc"
+	"github.com/graymeta/gmkit/http/httpc/httpcfakes"
+)
+
+func TestRequest_Hedge(t *testing.T) {
+	t.Run("slow first request is hedged", func(t *testing.T) {
+		var calls int32
+		var firstCanceled int32
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoStub = func(r *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-r.Context().Done()
+				atomic.StoreInt32(&firstCanceled, 1)
+				return nil, r.Context().Err()
+			}
+			return stubRespNBody(t, http.StatusOK, foo{Name: "hedged"}), nil
+		}
+
+		client := httpc.New(doer)
+
+		var actual foo
+		err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			Hedge(10*time.Millisecond, 2).
+			DecodeJSON(&actual).
+			Do(context.TODO())
+		require.NoError(t, err)
+
+		assert.Equal(t, "hedged", actual.Name)
+		assert.Equal(t, 2, doer.DoCallCount())
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(&firstCanceled) == 1
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("fast request is not hedged", func(t *testing.T) {
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoReturns(stubResp(http.StatusOK), nil)
+
+		client := httpc.New(doer)
+
+		resp, err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			Hedge(time.Second, 3).
+			DoAndGetReader(context.TODO())
+		defer drain(resp)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, doer.DoCallCount())
+	})
+
+	t.Run("does not exceed max", func(t *testing.T) {
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoStub = func(r *http.Request) (*http.Response, error) {
+			time.Sleep(50 * time.Millisecond)
+			return stubResp(http.StatusInternalServerError), nil
+		}
+
+		client := httpc.New(doer)
+
+		err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			Hedge(time.Millisecond, 3).
+			Do(context.TODO())
+		require.Error(t, err)
+
+		assert.Equal(t, 3, doer.DoCallCount())
+	})
+
+	t.Run("failed hedged round is retried by backoff", func(t *testing.T) {
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoStub = func(r *http.Request) (*http.Response, error) {
+			time.Sleep(20 * time.Millisecond)
+			return stubResp(http.StatusInternalServerError), nil
+		}
+
+		boffer := backoff.New(
+			backoff.InitBackoff(time.Nanosecond),
+			backoff.MaxBackoff(time.Nanosecond),
+			backoff.MaxCalls(2),
+		)
+		client := httpc.New(doer, httpc.WithBackoff(boffer))
+
+		err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			RetryStatus(httpc.StatusInternalServerError()).
+			Hedge(time.Millisecond, 2).
+			Do(context.TODO())
+		require.Error(t, err)
+		isRetryErr(t, err)
+
+		assert.Equal(t, 4, doer.DoCallCount())
+	})
+
+	t.Run("non idempotent methods are not hedged", func(t *testing.T) {
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoStub = func(r *http.Request) (*http.Response, error) {
+			time.Sleep(20 * time.Millisecond)
+			return stubResp(http.StatusOK), nil
+		}
+
+		client := httpc.New(doer)
+
+		err := client.
+			POST("/foo").
+			Body(foo{Name: "name"}).
+			Success(httpc.StatusOK()).
+			Hedge(time.Millisecond, 3).
+			Do(context.TODO())
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, doer.DoCallCount())
+	})
+}
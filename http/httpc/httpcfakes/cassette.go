@@ -0,0 +1,289 @@
+package httpcfakes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// RedactedValue replaces the value of any header or query param that is
+// redacted before being written to a cassette.
+const RedactedValue = "REDACTED"
+
+// Interaction is a single recorded request/response pair.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is the recorded portion of a request.
+type CassetteRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// CassetteResponse is the recorded portion of a response.
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Matcher determines whether a recorded request matches the request being made.
+type Matcher func(req, recorded CassetteRequest) bool
+
+// MatchMethod matches on the request method.
+func MatchMethod() Matcher {
+	return func(req, recorded CassetteRequest) bool {
+		return req.Method == recorded.Method
+	}
+}
+
+// MatchURL matches on the full request url, including the query.
+func MatchURL() Matcher {
+	return func(req, recorded CassetteRequest) bool {
+		return req.URL == recorded.URL
+	}
+}
+
+// MatchBody matches on the request body.
+func MatchBody() Matcher {
+	return func(req, recorded CassetteRequest) bool {
+		return req.Body == recorded.Body
+	}
+}
+
+// MatchHeaders matches on the values of the provided header keys.
+func MatchHeaders(key string, keys ...string) Matcher {
+	return func(req, recorded CassetteRequest) bool {
+		for _, k := range append(keys, key) {
+			if req.Headers.Get(k) != recorded.Headers.Get(k) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// CassetteOptFn sets optional fields on a Cassette.
+type CassetteOptFn func(c *Cassette)
+
+// WithMatchers sets the matchers used to find a recorded interaction during
+// replay. Every matcher must match. Defaults to MatchMethod and MatchURL.
+func WithMatchers(matchers ...Matcher) CassetteOptFn {
+	return func(c *Cassette) {
+		c.matchers = matchers
+	}
+}
+
+// WithRedactedHeaders adds header keys whose values are redacted before being
+// recorded. Authorization and Proxy-Authorization are always redacted.
+func WithRedactedHeaders(keys ...string) CassetteOptFn {
+	return func(c *Cassette) {
+		for _, k := range keys {
+			c.redactHeaders = append(c.redactHeaders, http.CanonicalHeaderKey(k))
+		}
+	}
+}
+
+// Cassette is a Doer that either records the interactions of a wrapped Doer to
+// a JSON fixture, or replays the interactions of a previously recorded fixture.
+type Cassette struct {
+	path          string
+	doer          doer
+	matchers      []Matcher
+	redactHeaders []string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+type doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// NewRecorder creates a Cassette that records all interactions made with the
+// provided doer. Call Save to write the fixture to path.
+func NewRecorder(d doer, path string, opts ...CassetteOptFn) *Cassette {
+	return newCassette(d, path, opts...)
+}
+
+// LoadCassette creates a Cassette that replays the interactions stored in the
+// fixture at path.
+func LoadCassette(path string, opts ...CassetteOptFn) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newCassette(nil, path, opts...)
+	if err := json.Unmarshal(b, &c.interactions); err != nil {
+		return nil, fmt.Errorf("decoding cassette %s: %w", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+func newCassette(d doer, path string, opts ...CassetteOptFn) *Cassette {
+	c := &Cassette{
+		path:          path,
+		doer:          d,
+		matchers:      []Matcher{MatchMethod(), MatchURL()},
+		redactHeaders: []string{"Authorization", "Proxy-Authorization"},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Do records or replays the request, depending on how the Cassette was created.
+func (c *Cassette) Do(req *http.Request) (*http.Response, error) {
+	recorded, err := c.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.doer == nil {
+		return c.replay(req, recorded)
+	}
+	return c.record(req, recorded)
+}
+
+// Interactions returns the interactions recorded or loaded by the Cassette.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Save writes the recorded interactions to the cassette's path.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := json.MarshalIndent(c.interactions, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, b, 0644)
+}
+
+func (c *Cassette) record(req *http.Request, recorded CassetteRequest) (*http.Response, error) {
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		body, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, Interaction{
+		Request: recorded,
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    c.redact(resp.Header),
+			Body:       string(body),
+		},
+	})
+	c.used = append(c.used, true)
+
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, recorded CassetteRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, in := range c.interactions {
+		if c.used[i] || !c.matches(recorded, in.Request) {
+			continue
+		}
+		c.used[i] = true
+
+		return &http.Response{
+			StatusCode:    in.Response.StatusCode,
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			Header:        in.Response.Headers.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewBufferString(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no cassette interaction in %s matches %s %s", c.path, recorded.Method, recorded.URL)
+}
+
+func (c *Cassette) matches(req, recorded CassetteRequest) bool {
+	for _, m := range c.matchers {
+		if !m(req, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Cassette) recordRequest(req *http.Request) (CassetteRequest, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return CassetteRequest{}, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return CassetteRequest{
+		Method:  req.Method,
+		URL:     redactURL(*req.URL),
+		Headers: c.redact(req.Header),
+		Body:    string(body),
+	}, nil
+}
+
+func (c *Cassette) redact(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	h = h.Clone()
+	for _, k := range c.redactHeaders {
+		if _, ok := h[k]; ok {
+			h.Set(k, RedactedValue)
+		}
+	}
+	return h
+}
+
+// redactURL redacts credentials the same way gmkit/errors does for client
+// errors.
+func redactURL(u url.URL) string {
+	if u.User != nil {
+		u.User = url.User(RedactedValue)
+	}
+	q := u.Query()
+	for _, k := range []string{"access_token", "secret"} {
+		if q.Get(k) != "" {
+			q.Set(k, RedactedValue)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
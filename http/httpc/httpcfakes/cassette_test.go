@@ -0,0 +1,140 @@
+package httpcfakes_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/graymeta/gmkit/http/httpc"
+	"github.com/graymeta/gmkit/http/httpc/httpcfakes"
+)
+
+type widget struct {
+	Name string `json:"name"`
+}
+
+func TestCassette(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write(b)
+		case r.URL.Query().Get("name") != "":
+			w.Write([]byte(`{"name":"` + r.URL.Query().Get("name") + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder := httpcfakes.NewRecorder(http.DefaultClient, path)
+	client := httpc.New(recorder,
+		httpc.WithBaseURL(svr.URL),
+		httpc.WithAuth(httpc.BearerTokenAuth("sekret")),
+	)
+
+	var got widget
+	err := client.
+		GET("/widgets").
+		QueryParams("name", "first", "access_token", "sekret").
+		Success(httpc.StatusOK()).
+		DecodeJSON(&got).
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "first", got.Name)
+
+	err = client.
+		POST("/widgets").
+		Body(widget{Name: "created"}).
+		Success(httpc.StatusCreated()).
+		DecodeJSON(&got).
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "created", got.Name)
+
+	require.NoError(t, recorder.Save())
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(b), "sekret"), "credentials were not redacted")
+
+	t.Run("replays interactions", func(t *testing.T) {
+		cassette, err := httpcfakes.LoadCassette(path)
+		require.NoError(t, err)
+		require.Len(t, cassette.Interactions(), 2)
+
+		client := httpc.New(cassette,
+			httpc.WithBaseURL(svr.URL),
+			httpc.WithAuth(httpc.BearerTokenAuth("other")),
+		)
+
+		var got widget
+		err = client.
+			GET("/widgets").
+			QueryParams("name", "first", "access_token", "other").
+			Success(httpc.StatusOK()).
+			DecodeJSON(&got).
+			Do(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, "first", got.Name)
+
+		err = client.
+			GET("/widgets").
+			QueryParams("name", "first", "access_token", "other").
+			Success(httpc.StatusOK()).
+			Do(context.TODO())
+		require.Error(t, err, "interactions should only be replayed once")
+	})
+
+	t.Run("matches on body", func(t *testing.T) {
+		cassette, err := httpcfakes.LoadCassette(path, httpcfakes.WithMatchers(
+			httpcfakes.MatchMethod(),
+			httpcfakes.MatchBody(),
+		))
+		require.NoError(t, err)
+
+		client := httpc.New(cassette)
+
+		err = client.
+			POST("/anywhere").
+			Body(widget{Name: "different"}).
+			Success(httpc.StatusCreated()).
+			Do(context.TODO())
+		require.Error(t, err)
+
+		var got widget
+		err = client.
+			POST("/anywhere").
+			Body(widget{Name: "created"}).
+			Success(httpc.StatusCreated()).
+			DecodeJSON(&got).
+			Do(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, "created", got.Name)
+	})
+
+	t.Run("matches on headers", func(t *testing.T) {
+		cassette, err := httpcfakes.LoadCassette(path, httpcfakes.WithMatchers(
+			httpcfakes.MatchMethod(),
+			httpcfakes.MatchHeaders("Authorization"),
+		))
+		require.NoError(t, err)
+
+		err = httpc.New(cassette, httpc.WithAuth(httpc.BearerTokenAuth("other"))).
+			GET("/widgets").
+			Success(httpc.StatusOK()).
+			Do(context.TODO())
+		require.NoError(t, err, "redacted headers should match regardless of value")
+	})
+}
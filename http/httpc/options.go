@@ -1,6 +1,10 @@
 package httpc
 
-import "github.com/graymeta/gmkit/backoff"
+import (
+	"time"
+
+	"github.com/graymeta/gmkit/backoff"
+)
 
 // ClientOptFn sets keys on a client type.
 type ClientOptFn func(Client) Client
@@ -40,6 +44,24 @@ func WithEncode(fn EncodeFn) ClientOptFn {
 	}
 }
 
+// WithMaxResponseBytes sets the max size of the response body that will be read
+// for all requests from this client.
+func WithMaxResponseBytes(n int64) ClientOptFn {
+	return func(c Client) Client {
+		c.maxResponseBytes = n
+		return c
+	}
+}
+
+// WithReadTimeout sets the max duration allowed for reading the response body
+// once the response headers have been received for all requests from this client.
+func WithReadTimeout(d time.Duration) ClientOptFn {
+	return func(c Client) Client {
+		c.readTimeout = d
+		return c
+	}
+}
+
 // WithRetryClientTimeouts sets the response retry mechanism. Useful if you want to
 // retry on a client timeout or something of that nature.
 func WithRetryClientTimeouts() ClientOptFn {
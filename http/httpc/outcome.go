@@ -0,0 +1,152 @@
+package httpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Outcome describes the result of a logical call made by a Request, across
+// all of the attempts made by the backoff.
+type Outcome struct {
+	Method string
+	// URL is the address of the request without any query params.
+	URL           string
+	StatusCode    int
+	Attempts      []Attempt
+	BytesSent     int64
+	BytesReceived int64
+	Header        http.Header
+	Duration      time.Duration
+	Err           error
+}
+
+// Attempt describes a single attempt made within the backoff.
+type Attempt struct {
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// ObserveFn is a function that receives the outcome of a request.
+type ObserveFn func(Outcome)
+
+// Observe sets a hook that is called with the Outcome of the request once it
+// has completed. When using DoAndGetReader, the hook is called once the body of
+// the returned response is closed so that the bytes received can be counted.
+func (r *Request) Observe(fn ObserveFn) *Request {
+	r.observeFn = fn
+	return r
+}
+
+// outcomeRecorder records the outcome of a request. A nil outcomeRecorder is
+// safe to use and records nothing.
+type outcomeRecorder struct {
+	fn      ObserveFn
+	start   time.Time
+	outcome Outcome
+	current Attempt
+	sent    int64
+	recv    int64
+	once    sync.Once
+}
+
+func (r *Request) newOutcomeRecorder() *outcomeRecorder {
+	if r.observeFn == nil {
+		return nil
+	}
+	return &outcomeRecorder{
+		fn:    r.observeFn,
+		start: time.Now(),
+		outcome: Outcome{
+			Method: r.method,
+			URL:    strings.SplitN(r.addr, "?", 2)[0],
+		},
+	}
+}
+
+// attempt wraps the func called by the backoff to record each attempt.
+func (o *outcomeRecorder) attempt(fn func(context.Context) error) func(context.Context) error {
+	if o == nil {
+		return fn
+	}
+	return func(ctx context.Context) error {
+		start := time.Now()
+		o.current = Attempt{}
+		err := fn(ctx)
+		o.current.Duration = time.Since(start)
+		o.current.Err = err
+		o.outcome.Attempts = append(o.outcome.Attempts, o.current)
+		return err
+	}
+}
+
+// request counts the bytes sent in the body of the request.
+func (o *outcomeRecorder) request(req *http.Request) {
+	if o == nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	req.Body = &countingBody{ReadCloser: req.Body, n: &o.sent}
+}
+
+// response records the status and headers of the response and counts the bytes
+// received in its body.
+func (o *outcomeRecorder) response(resp *http.Response) {
+	if o == nil || resp == nil {
+		return
+	}
+	o.current.StatusCode = resp.StatusCode
+	o.outcome.StatusCode = resp.StatusCode
+	o.outcome.Header = resp.Header
+	if resp.Body != nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, n: &o.recv}
+	}
+}
+
+// finish calls the observe func with the outcome. Only the first call to finish
+// has any effect.
+func (o *outcomeRecorder) finish(err error) {
+	if o == nil {
+		return
+	}
+	o.once.Do(func() {
+		o.outcome.Err = err
+		o.outcome.Duration = time.Since(o.start)
+		o.outcome.BytesSent = atomic.LoadInt64(&o.sent)
+		o.outcome.BytesReceived = atomic.LoadInt64(&o.recv)
+		o.fn(o.outcome)
+	})
+}
+
+// finishOnClose defers finishing the outcome until the response body is closed.
+func (o *outcomeRecorder) finishOnClose(resp *http.Response) {
+	if o == nil {
+		return
+	}
+	resp.Body = &closeHookBody{ReadCloser: resp.Body, onClose: func() { o.finish(nil) }}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (c *countingBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+type closeHookBody struct {
+	io.ReadCloser
+	onClose func()
+}
+
+func (c *closeHookBody) Close() error {
+	defer c.onClose()
+	return c.ReadCloser.Close()
+}
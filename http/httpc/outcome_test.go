@@ -0,0 +1,132 @@
+package httpc_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/graymeta/gmkit/backoff"
+	"github.com/graymeta/gmkit/http/httpc"
+	"github.com/graymeta/gmkit/http/httpc/httpcfakes"
+)
+
+func TestRequest_Observe(t *testing.T) {
+	boffer := backoff.New(
+		backoff.InitBackoff(time.Nanosecond),
+		backoff.MaxBackoff(time.Nanosecond),
+		backoff.MaxCalls(3),
+	)
+
+	newDoer := func() *httpcfakes.FakeDoer {
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoReturnsOnCall(0, stubResp(http.StatusServiceUnavailable), nil)
+		doer.DoReturnsOnCall(1, stubRespHeaders(http.StatusOK, map[string]string{"X-Foo": "bar"}), nil)
+		return doer
+	}
+
+	t.Run("Do", func(t *testing.T) {
+		doer := newDoer()
+		doer.DoReturnsOnCall(1, stubRespNBody(t, http.StatusOK, foo{Name: "name"}), nil)
+		client := httpc.New(doer, httpc.WithBackoff(boffer), httpc.WithBaseURL("http://example.com"))
+
+		var outcome httpc.Outcome
+		var calls int
+		err := client.
+			POST("/foo").
+			QueryParam("secret", "shh").
+			Body(foo{Name: "name"}).
+			Success(httpc.StatusOK()).
+			RetryStatus(httpc.StatusIn(http.StatusServiceUnavailable)).
+			Observe(func(o httpc.Outcome) {
+				calls++
+				outcome = o
+			}).
+			DecodeJSON(new(foo)).
+			Do(context.TODO())
+		require.NoError(t, err)
+
+		require.Equal(t, 1, calls)
+		assert.Equal(t, http.MethodPost, outcome.Method)
+		assert.Equal(t, "http://example.com/foo", outcome.URL)
+		assert.Equal(t, http.StatusOK, outcome.StatusCode)
+		require.Len(t, outcome.Attempts, 2)
+		assert.Equal(t, http.StatusServiceUnavailable, outcome.Attempts[0].StatusCode)
+		assert.Error(t, outcome.Attempts[0].Err)
+		assert.Equal(t, http.StatusOK, outcome.Attempts[1].StatusCode)
+		assert.NoError(t, outcome.Attempts[1].Err)
+		assert.NoError(t, outcome.Err)
+		assert.Zero(t, outcome.BytesSent, "fake doer never reads the request body")
+		assert.Equal(t, int64(len(`{"Name":"name","S":"","Method":""}`+"\n")), outcome.BytesReceived)
+	})
+
+	t.Run("Do with error", func(t *testing.T) {
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoReturns(stubResp(http.StatusServiceUnavailable), nil)
+		client := httpc.New(doer, httpc.WithBackoff(boffer))
+
+		var outcome httpc.Outcome
+		err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			RetryStatus(httpc.StatusIn(http.StatusServiceUnavailable)).
+			Observe(func(o httpc.Outcome) { outcome = o }).
+			Do(context.TODO())
+		require.Error(t, err)
+
+		assert.Len(t, outcome.Attempts, 3)
+		assert.Equal(t, http.StatusServiceUnavailable, outcome.StatusCode)
+		assert.Equal(t, err, outcome.Err)
+	})
+
+	t.Run("DoAndGetReader observes on close", func(t *testing.T) {
+		doer := newDoer()
+		client := httpc.New(doer, httpc.WithBackoff(boffer))
+
+		var observed bool
+		var outcome httpc.Outcome
+		resp, err := client.
+			GET("/foo").
+			Success(httpc.StatusOK()).
+			RetryStatus(httpc.StatusIn(http.StatusServiceUnavailable)).
+			Observe(func(o httpc.Outcome) {
+				observed = true
+				outcome = o
+			}).
+			DoAndGetReader(context.TODO())
+		require.NoError(t, err)
+		assert.False(t, observed)
+
+		_, err = ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		require.True(t, observed)
+		assert.Len(t, outcome.Attempts, 2)
+		assert.Equal(t, "bar", outcome.Header.Get("X-Foo"))
+	})
+
+	t.Run("counts bytes sent", func(t *testing.T) {
+		doer := new(httpcfakes.FakeDoer)
+		doer.DoStub = func(r *http.Request) (*http.Response, error) {
+			ioutil.ReadAll(r.Body)
+			return stubResp(http.StatusOK), nil
+		}
+		client := httpc.New(doer)
+
+		var outcome httpc.Outcome
+		err := client.
+			POST("/foo").
+			Body(foo{Name: "name"}).
+			Success(httpc.StatusOK()).
+			Observe(func(o httpc.Outcome) { outcome = o }).
+			Do(context.TODO())
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(len(`{"Name":"name","S":"","Method":""}`+"\n")), outcome.BytesSent)
+	})
+}
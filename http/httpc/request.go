@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/graymeta/gmkit/backoff"
 	gmerrors "github.com/graymeta/gmkit/errors"
@@ -50,12 +51,17 @@ type Request struct {
 	onErrorFn         DecodeFn
 	responseErrFn     ResponseErrorFn
 	responseHeadersFn ResponseHeadersFn
+	observeFn         ObserveFn
 
 	existsFns      []StatusFn
 	notFoundFns    []StatusFn
 	retryStatusFns []StatusFn
 	successFns     []StatusFn
 	backoff        backoff.Backoffer
+	hedge          *hedgeParams
+
+	maxResponseBytes int64
+	readTimeout      time.Duration
 }
 
 // Auth sets the authorization for hte request, overriding the authFn set
@@ -122,12 +128,33 @@ func (r *Request) Exists(fn StatusFn) *Request {
 	return r
 }
 
+// Hedge sets the request to be hedged. If a response has not been received
+// after the provided duration, an identical request is sent, up to max requests
+// in flight. The first successful response is used and the remaining requests
+// are canceled. Hedging only applies to GET and HEAD requests without a body,
+// and each hedged round counts as a single call to the backoff.
+func (r *Request) Hedge(after time.Duration, max int) *Request {
+	r.hedge = &hedgeParams{
+		after: after,
+		max:   max,
+	}
+	return r
+}
+
 // Header adds a header to the request.
 func (r *Request) Header(key, value string) *Request {
 	r.headers = append(r.headers, kvPair{key: key, value: value})
 	return r
 }
 
+// MaxResponseBytes limits the size of the response body that will be read,
+// overriding the limit set by the client. Reading beyond the limit returns
+// ErrResponseTooLarge.
+func (r *Request) MaxResponseBytes(n int64) *Request {
+	r.maxResponseBytes = n
+	return r
+}
+
 // Meta adds k/v pairs to the eror message be added in the event of an error.
 func (r *Request) Meta(key, value string, pairs ...string) *Request {
 	r.logMeta = append(r.logMeta, kvPair{key: key, value: value})
@@ -147,6 +174,14 @@ func (r *Request) OnError(fn DecodeFn) *Request {
 	return r
 }
 
+// ReadTimeout sets the max duration allowed for reading the response body once
+// the response headers have been received, overriding the timeout set by the
+// client. Reading after the timeout returns ErrReadTimeout.
+func (r *Request) ReadTimeout(d time.Duration) *Request {
+	r.readTimeout = d
+	return r
+}
+
 // ResponseHeaders provides a hook to get the headers of a response
 func (r *Request) ResponseHeaders(fn ResponseHeadersFn) *Request {
 	r.responseHeadersFn = fn
@@ -194,96 +229,131 @@ func (r *Request) Success(fn StatusFn) *Request {
 	return r
 }
 
-// DoAndGetReader makes the http request and does not close the body in the http.Response that is returned
+// DoAndGetReader makes the http request and does not close the body in the
+// http.Response that is returned. The decode func is not applied, the caller is
+// responsible for reading and closing the body.
 func (r *Request) DoAndGetReader(ctx context.Context) (*http.Response, error) {
-	var resp *http.Response
-	err := r.backoff.BackoffCtx(ctx, func(ctx context.Context) error {
-		body, err := r.getReqBody()
-		if err != nil {
-			return gmerrors.NewClientErr("encode body", err, nil, r.metaErrOpts()...)
-		}
+	outcome := r.newOutcomeRecorder()
+	resp, err := r.open(ctx, outcome)
+	if err != nil {
+		outcome.finish(err)
+		return nil, err
+	}
 
-		req, err := http.NewRequest(r.method, r.addr, body)
-		if err != nil {
-			return gmerrors.NewClientErr("new req", err, nil, r.metaErrOpts()...)
-		}
-		req = req.WithContext(ctx)
+	outcome.finishOnClose(resp)
+	return resp, nil
+}
 
-		if len(r.headers) > 0 {
-			for _, pair := range r.headers {
-				req.Header.Set(pair.key, pair.value)
-			}
+// Do makes the http request and applies the backoff. The response body is
+// decoded with the decode func within the backoff, so a decode error that is
+// retriable will retry the request.
+func (r *Request) Do(ctx context.Context) error {
+	outcome := r.newOutcomeRecorder()
+	err := r.backoff.BackoffCtx(ctx, outcome.attempt(func(ctx context.Context) error {
+		resp, err := r.exec(ctx, outcome)
+		if err != nil {
+			return err
 		}
+		defer drain(resp.Body)
 
-		if len(r.params) > 0 {
-			params := req.URL.Query()
-			for _, kv := range r.params {
-				params.Set(kv.key, kv.value)
-			}
-			req.URL.RawQuery = params.Encode()
-		}
+		return r.decode(resp)
+	}))
+	outcome.finish(err)
+	return err
+}
 
-		if r.authFn != nil {
-			req = r.authFn(req)
-		}
+// Stream makes the http request, applying the backoff until a successful
+// response is received, then streams the response body through fn. Unlike the
+// decode func provided to Do, fn is called at most once and is never retried.
+func (r *Request) Stream(ctx context.Context, fn DecodeFn) error {
+	outcome := r.newOutcomeRecorder()
+	resp, err := r.open(ctx, outcome)
+	if err != nil {
+		outcome.finish(err)
+		return err
+	}
+	defer drain(resp.Body)
 
-		if r.contentLength > 0 {
-			req.ContentLength = int64(r.contentLength)
-		}
+	if err := fn(resp.Body); err != nil {
+		err = gmerrors.NewClientErr("stream", err, nil, r.metaErrOpts()...)
+		outcome.finish(err)
+		return err
+	}
+	outcome.finish(nil)
+	return nil
+}
 
-		resp, err = r.doer.Do(req)
-		if err != nil {
-			return r.responseErr(resp, err)
-		}
-		if r.responseHeadersFn != nil {
-			r.responseHeadersFn(resp.Header)
-		}
+// open applies the backoff until a successful response is received and returns
+// the response with an open body.
+func (r *Request) open(ctx context.Context, outcome *outcomeRecorder) (*http.Response, error) {
+	var resp *http.Response
+	err := r.backoff.BackoffCtx(ctx, outcome.attempt(func(ctx context.Context) error {
+		var err error
+		resp, err = r.exec(ctx, outcome)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
 
-		status := resp.StatusCode
-		if !statusMatches(status, r.successFns) {
-			defer func() {
-				drain(resp.Body)
-			}()
-			var err error
-			if r.onErrorFn != nil {
-				var buf bytes.Buffer
-				tee := io.TeeReader(resp.Body, &buf)
-				err = r.onErrorFn(tee)
-				resp.Body = ioutil.NopCloser(&buf)
-			}
-			return gmerrors.NewClientErr("status code", err, resp, r.statusErrOpts(status)...)
-		}
+// exec makes a single attempt at the http request. When the response status is
+// a success, the response is returned with its body open and limited by the
+// max response bytes and read timeout. Otherwise the body is drained and an
+// error is returned.
+func (r *Request) exec(ctx context.Context, outcome *outcomeRecorder) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
 
-		return nil
-	})
+	req, err := r.newHTTPRequest(ctx)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	return resp, nil
-}
+	outcome.request(req)
+	resp, err := r.send(req)
+	outcome.response(resp)
+	if err != nil {
+		cancel()
+		return nil, r.responseErr(resp, err)
+	}
+	if r.responseHeadersFn != nil {
+		r.responseHeadersFn(resp.Header)
+	}
+	resp.Body = newResponseBody(resp.Body, r.maxResponseBytes, r.readTimeout, cancel)
 
-// Do makes the http request and applies the backoff.
-func (r *Request) Do(ctx context.Context) error {
-	return r.backoff.BackoffCtx(ctx, r.do)
+	status := resp.StatusCode
+	if !statusMatches(status, r.successFns) {
+		defer drain(resp.Body)
+
+		var err error
+		if r.onErrorFn != nil {
+			var buf bytes.Buffer
+			tee := io.TeeReader(resp.Body, &buf)
+			err = r.onErrorFn(tee)
+			resp.Body = ioutil.NopCloser(&buf)
+		}
+		return nil, gmerrors.NewClientErr("status code", err, resp, r.statusErrOpts(status)...)
+	}
+
+	return resp, nil
 }
 
-func (r *Request) do(ctx context.Context) error {
+func (r *Request) newHTTPRequest(ctx context.Context) (*http.Request, error) {
 	body, err := r.getReqBody()
 	if err != nil {
-		return gmerrors.NewClientErr("encode body", err, nil, r.metaErrOpts()...)
+		return nil, gmerrors.NewClientErr("encode body", err, nil, r.metaErrOpts()...)
 	}
 
 	req, err := http.NewRequest(r.method, r.addr, body)
 	if err != nil {
-		return gmerrors.NewClientErr("new req", err, nil, r.metaErrOpts()...)
+		return nil, gmerrors.NewClientErr("new req", err, nil, r.metaErrOpts()...)
 	}
 	req = req.WithContext(ctx)
 
-	if len(r.headers) > 0 {
-		for _, pair := range r.headers {
-			req.Header.Set(pair.key, pair.value)
-		}
+	for _, pair := range r.headers {
+		req.Header.Set(pair.key, pair.value)
 	}
 
 	if len(r.params) > 0 {
@@ -302,29 +372,10 @@ func (r *Request) do(ctx context.Context) error {
 		req.ContentLength = int64(r.contentLength)
 	}
 
-	resp, err := r.doer.Do(req)
-	if err != nil {
-		return r.responseErr(resp, err)
-	}
-	if r.responseHeadersFn != nil {
-		r.responseHeadersFn(resp.Header)
-	}
-	defer func() {
-		drain(resp.Body)
-	}()
-
-	status := resp.StatusCode
-	if !statusMatches(status, r.successFns) {
-		var err error
-		if r.onErrorFn != nil {
-			var buf bytes.Buffer
-			tee := io.TeeReader(resp.Body, &buf)
-			err = r.onErrorFn(tee)
-			resp.Body = ioutil.NopCloser(&buf)
-		}
-		return gmerrors.NewClientErr("status code", err, resp, r.statusErrOpts(status)...)
-	}
+	return req, nil
+}
 
+func (r *Request) decode(resp *http.Response) error {
 	if r.decodeFn == nil {
 		return nil
 	}
@@ -347,7 +398,9 @@ func (r *Request) getReqBody() (io.Reader, error) {
 
 	if reader, ok := r.body.(io.Reader); ok {
 		if seeker, ok2 := r.body.(io.Seeker); ok2 && r.seekParams != nil {
-			seeker.Seek(r.seekParams.offset, r.seekParams.whence)
+			if _, err := seeker.Seek(r.seekParams.offset, r.seekParams.whence); err != nil {
+				return nil, err
+			}
 		}
 		return reader, nil
 	}
@@ -416,10 +469,11 @@ func toKVPairs(pairs []string) []kvPair {
 
 // drain reads everything from the ReadCloser and closes it
 func drain(r io.ReadCloser) error {
-	if _, err := io.Copy(ioutil.Discard, r); err != nil {
-		return err
+	_, err := io.Copy(ioutil.Discard, r)
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
 	}
-	return r.Close()
+	return err
 }
 
 func isRetryErr(err error) bool {
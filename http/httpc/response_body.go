@@ -0,0 +1,75 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrResponseTooLarge is returned when reading a response body that exceeds
+	// the max response bytes.
+	ErrResponseTooLarge = errors.New("response body exceeds max response bytes")
+
+	// ErrReadTimeout is returned when reading a response body after the read
+	// timeout has elapsed.
+	ErrReadTimeout = errors.New("timed out reading response body")
+)
+
+// responseBody enforces the max response bytes and read timeout on a response
+// body, and cancels the context of the request once the body is closed.
+type responseBody struct {
+	io.ReadCloser
+	limit    int64
+	read     int64
+	timer    *time.Timer
+	timedOut int32
+	cancel   context.CancelFunc
+}
+
+func newResponseBody(body io.ReadCloser, limit int64, timeout time.Duration, cancel context.CancelFunc) *responseBody {
+	b := &responseBody{
+		ReadCloser: body,
+		limit:      limit,
+		cancel:     cancel,
+	}
+	if timeout > 0 {
+		b.timer = time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&b.timedOut, 1)
+			cancel()
+		})
+	}
+	return b
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	if atomic.LoadInt32(&b.timedOut) == 1 {
+		return 0, ErrReadTimeout
+	}
+	if b.limit > 0 && b.read > b.limit {
+		return 0, ErrResponseTooLarge
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && atomic.LoadInt32(&b.timedOut) == 1 {
+		err = ErrReadTimeout
+	}
+
+	if b.limit > 0 {
+		b.read += int64(n)
+		if over := b.read - b.limit; over > 0 {
+			return n - int(over), ErrResponseTooLarge
+		}
+	}
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
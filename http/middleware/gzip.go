@@ -9,14 +9,15 @@ import (
 
 type gzipWriter struct {
 	http.ResponseWriter
-	w io.Writer
+	gz *gzip.Writer
+	w  io.Writer
 }
 
-func (gzw gzipWriter) Write(b []byte) (int, error) {
+func (gzw *gzipWriter) Write(b []byte) (int, error) {
 	return gzw.w.Write(b)
 }
 
-func (gzw gzipWriter) WriterHeader(statusCode int) {
+func (gzw *gzipWriter) WriteHeader(statusCode int) {
 	// gzw.w.Close() (gzip writer) will write a footer even if no data has been written.
 	// StatusNotModified and StatusNoContent expect an empty body so swap for generic ResponseWriter instead
 	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
@@ -25,6 +26,17 @@ func (gzw gzipWriter) WriterHeader(statusCode int) {
 	gzw.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Flush writes any compressed data buffered by the gzip writer to the client, so
+// that streamed responses are not held back until the handler returns.
+func (gzw *gzipWriter) Flush() {
+	if gzw.w == io.Writer(gzw.gz) {
+		gzw.gz.Flush()
+	}
+	if f, ok := gzw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Compress is middleware that implements gzip compression
 func Compress(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -34,8 +46,11 @@ func Compress(h http.Handler) http.Handler {
 		}
 		w.Header().Set("Content-Encoding", "gzip")
 		gz := gzip.NewWriter(w)
-		defer gz.Close()
-		gzw := gzipWriter{w: gz, ResponseWriter: w}
+		gzw := &gzipWriter{ResponseWriter: w, gz: gz, w: gz}
 		h.ServeHTTP(gzw, r)
+		// a response without a body must not get the gzip header and footer either.
+		if gzw.w == io.Writer(gz) {
+			gz.Close()
+		}
 	})
 }
@@ -1,12 +1,14 @@
 package middleware
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -66,6 +68,56 @@ func TestNoContent(t *testing.T) {
 	require.Len(t, b, 0)
 }
 
+func TestGzipEmptyBody(t *testing.T) {
+	for _, status := range []int{http.StatusNoContent, http.StatusNotModified} {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Add("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		Compress(handler).ServeHTTP(rec, req)
+
+		require.Equal(t, status, rec.Code)
+		require.Zero(t, rec.Body.Len(), "status %d", status)
+	}
+}
+
+func TestGzipFlush(t *testing.T) {
+	flushed := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first\n"))
+		w.(http.Flusher).Flush()
+		<-flushed
+		w.Write([]byte("second\n"))
+	})
+	srv := httptest.NewServer(Compress(handler))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Add("Accept-Encoding", "gzip")
+	res, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	gr, err := gzip.NewReader(res.Body)
+	require.NoError(t, err)
+
+	// the first line must arrive before the handler writes the second.
+	defer close(flushed)
+	lines := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(gr).ReadString('\n')
+		lines <- line
+	}()
+	select {
+	case line := <-lines:
+		require.Equal(t, "first\n", line)
+	case <-time.After(time.Second):
+		t.Fatal("first line was not flushed")
+	}
+}
+
 var body = []byte(`{
 	"query": "png",
 	"limit": 10,
@@ -5,6 +5,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/graymeta/gmkit/testhelpers"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,14 +29,15 @@ func TestSetAllActiveChecksSuccess(t *testing.T) {
 		]
 	}`)
 
-	hler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(jsonData)
-	})
-
-	s := httptest.NewServer(hler)
-	defer s.Close()
+	s := testhelpers.NewMockServer(t)
+	for _, path := range []string{"/objects/services", "/objects/hosts", "/objects/services"} {
+		var body ActiveCheck
+		body.Filter = `host.name=="client1.example.com"`
+		body.Attrs.EnableActiveChecks = true
+		s.Expect(http.MethodPost, path).
+			WithJSONBody(body).
+			Respond(http.StatusOK, jsonData)
+	}
 
 	icingaCfg := Config{
 		BaseURL:  s.URL,
@@ -2,9 +2,10 @@ package icinga
 
 import (
 	"net/http"
-	"net/http/httptest"
 	"testing"
 
+	"github.com/graymeta/gmkit/testhelpers"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,14 +26,11 @@ func TestHostExistSuccess(t *testing.T) {
     ]
 	}`)
 
-	hler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(jsonData)
-	})
-
-	s := httptest.NewServer(hler)
-	defer s.Close()
+	s := testhelpers.NewMockServer(t)
+	s.Expect(http.MethodGet, "/objects/hosts").
+		WithHeader("Accept", "application/json").
+		WithJSONBody(Host{Filter: `host.name=="client1.example.com"`}).
+		Respond(http.StatusOK, jsonData)
 
 	icingaCfg := Config{
 		BaseURL:  s.URL,
@@ -54,14 +52,11 @@ func TestHostExistFailure(t *testing.T) {
 		"results": []
 	}`)
 
-	hler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(jsonData)
-	})
-
-	s := httptest.NewServer(hler)
-	defer s.Close()
+	s := testhelpers.NewMockServer(t)
+	s.Expect(http.MethodGet, "/objects/hosts").
+		WithHeader("Accept", "application/json").
+		WithJSONBody(Host{Filter: `host.name=="client1.example.com"`}).
+		Respond(http.StatusOK, jsonData)
 
 	icingaCfg := Config{
 		BaseURL:  s.URL,
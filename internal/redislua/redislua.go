@@ -0,0 +1,19 @@
+// Package redislua holds Lua snippets shared by the Redis scripts of several
+// packages.
+package redislua
+
+// Time defines the Lua function currentMicros, which returns the time of the
+// Redis server in microseconds, so that scripts do not depend on the clocks of
+// the processes running them.
+//
+// Scripts that write after reading TIME are only replicated safely as their
+// effects, so Time calls redis.replicate_commands, which is the default from
+// Redis 5 but must be called before any write on earlier versions.
+const Time = `
+	redis.replicate_commands()
+
+	local function currentMicros()
+		local t = redis.call('TIME')
+		return tonumber(t[1]) * 1000000 + tonumber(t[2])
+	end
+`
@@ -0,0 +1,65 @@
+// +build int
+
+package lock_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graymeta/gmkit/lock"
+	"github.com/graymeta/gmkit/lock/locktest"
+	"github.com/graymeta/gmkit/testhelpers/postgres"
+	"github.com/graymeta/gmkit/testhelpers/redis"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisLocker_Conformance(t *testing.T) {
+	locktest.TestLocker(t, func(t *testing.T) lock.Locker {
+		pool := redis.Setup(t)
+		t.Cleanup(func() { pool.Close() })
+		return lock.NewRedisLocker(pool, "somePrefix:")
+	})
+}
+
+func TestRedlock_Conformance(t *testing.T) {
+	locktest.TestLocker(t, func(t *testing.T) lock.Locker {
+		var pools []*redigo.Pool
+		for _, db := range []int{13, 14, 15} {
+			pool := redis.SetupDB(t, db)
+			t.Cleanup(func() { pool.Close() })
+			pools = append(pools, pool)
+		}
+		return lock.NewRedlock(pools, "somePrefix:")
+	})
+}
+
+func TestRWLocker_Conformance(t *testing.T) {
+	locktest.TestLocker(t, func(t *testing.T) lock.Locker {
+		pool := redis.Setup(t)
+		t.Cleanup(func() { pool.Close() })
+		return lock.NewRWLocker(pool, "somePrefix:")
+	})
+}
+
+func TestSemaphore_Conformance(t *testing.T) {
+	locktest.TestLocker(t, func(t *testing.T) lock.Locker {
+		pool := redis.Setup(t)
+		t.Cleanup(func() { pool.Close() })
+		return lock.NewSemaphore(pool, "somePrefix:", 1)
+	})
+}
+
+func TestPostgresLocker_Conformance(t *testing.T) {
+	locktest.TestLocker(t, func(t *testing.T) lock.Locker {
+		db := postgres.Setup(t)
+		t.Cleanup(func() { db.Close() })
+		_, err := db.Exec("DROP TABLE IF EXISTS test_locks")
+		require.NoError(t, err)
+
+		l := lock.NewPostgresLocker(db, "test_locks")
+		require.NoError(t, l.CreateTable(context.Background()))
+		return l
+	})
+}
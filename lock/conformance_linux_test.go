@@ -0,0 +1,18 @@
+package lock_test
+
+import (
+	"testing"
+
+	"github.com/graymeta/gmkit/lock"
+	"github.com/graymeta/gmkit/lock/locktest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLocker_Conformance(t *testing.T) {
+	locktest.TestLocker(t, func(t *testing.T) lock.Locker {
+		l, err := lock.NewFileLocker(t.TempDir())
+		require.NoError(t, err)
+		return l
+	})
+}
@@ -0,0 +1,14 @@
+package lock_test
+
+import (
+	"testing"
+
+	"github.com/graymeta/gmkit/lock"
+	"github.com/graymeta/gmkit/lock/locktest"
+)
+
+func TestMemoryLocker_Conformance(t *testing.T) {
+	locktest.TestLocker(t, func(t *testing.T) lock.Locker {
+		return lock.NewMemoryLocker()
+	})
+}
@@ -0,0 +1,170 @@
+// Package election provides leader election on top of a lock.Locker. Each
+// candidate campaigns for the same lock, and the candidate holding it is the
+// leader until it steps down or the lock is lost.
+package election
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/graymeta/gmkit/lock"
+	"github.com/graymeta/gmkit/metrics"
+)
+
+// ErrLeaderUnknown is returned by Leader when this candidate is not the leader
+// and the Locker cannot report which unique id holds the lock.
+var ErrLeaderUnknown = errors.New("leader unknown, locker does not report owners")
+
+// OptFn is a functional option for configuring an Elector.
+type OptFn func(e *Elector)
+
+// OnElected sets a func that is called in its own goroutine each time the
+// candidate is elected. The context is cancelled once the candidate is demoted,
+// and the candidate does not campaign again until fn has returned.
+func OnElected(fn func(ctx context.Context)) OptFn {
+	return func(e *Elector) {
+		e.onElected = fn
+	}
+}
+
+// OnDemoted sets a func that is called each time the candidate stops being the
+// leader, after the func set by OnElected has returned.
+func OnDemoted(fn func()) OptFn {
+	return func(e *Elector) {
+		e.onDemoted = fn
+	}
+}
+
+// TTL sets the duration of the leader's lock. A leader that stops refreshing
+// the lock, such as one that has crashed, is replaced once it expires.
+// Defaults to 30 seconds.
+func TTL(d time.Duration) OptFn {
+	return func(e *Elector) {
+		e.ttl = d
+	}
+}
+
+// AcquireOpts sets the options used when acquiring and refreshing the lock.
+func AcquireOpts(opts ...lock.AcquireOptFn) OptFn {
+	return func(e *Elector) {
+		e.acquireOpts = opts
+	}
+}
+
+// Elector campaigns to be the leader identified by name.
+type Elector struct {
+	locker      lock.Locker
+	unique      *lock.UniqueLocker
+	name        string
+	id          string
+	ttl         time.Duration
+	acquireOpts []lock.AcquireOptFn
+	onElected   func(ctx context.Context)
+	onDemoted   func()
+
+	mu     sync.Mutex
+	leader bool
+}
+
+// New initializes an Elector for the candidate with the given unique id.
+func New(l lock.Locker, name, uniqueID string, opts ...OptFn) *Elector {
+	e := &Elector{
+		locker:    l,
+		unique:    lock.NewUniqueLocker(l, uniqueID),
+		name:      name,
+		id:        uniqueID,
+		ttl:       30 * time.Second,
+		onElected: func(context.Context) {},
+		onDemoted: func() {},
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// Run campaigns to be the leader until the context is cancelled. When elected,
+// the lock is refreshed in the background until it is lost, at which point
+// the candidate is demoted and campaigns again. Once the context is cancelled a
+// leader steps down, waiting for the func set by OnElected to return before
+// releasing the lock so that another candidate can be elected immediately.
+// Run returns nil once the context is cancelled.
+func (e *Elector) Run(ctx context.Context) error {
+	for {
+		lease, err := e.unique.Acquire(ctx, e.name, e.ttl, e.acquireOpts...)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		e.lead(lease)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// lead runs the leader's term until the lease is lost or released.
+func (e *Elector) lead(lease *lock.Lease) {
+	e.setLeader(true)
+	metrics.Incr(e.stat("elected"), 1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.onElected(lease.Context())
+	}()
+
+	<-lease.Context().Done()
+	<-done
+	// releasing a lost lease is a noop, and a failed release expires with the
+	// ttl.
+	lease.Release()
+
+	e.setLeader(false)
+	metrics.Incr(e.stat("demoted"), 1)
+	e.onDemoted()
+}
+
+// IsLeader returns whether the candidate is currently the leader.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Leader returns the unique id of the current leader, or an empty string if
+// there is no leader. If the candidate is not the leader, the Locker must be a
+// lock.OwnerLocker for the leader to be known.
+func (e *Elector) Leader() (string, error) {
+	if e.IsLeader() {
+		return e.id, nil
+	}
+	ol, ok := e.locker.(lock.OwnerLocker)
+	if !ok {
+		return "", ErrLeaderUnknown
+	}
+	return ol.Owner(e.name)
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	e.leader = leader
+	e.mu.Unlock()
+
+	var v int64
+	if leader {
+		v = 1
+	}
+	metrics.Gauge(e.stat("leader"), v)
+}
+
+func (e *Elector) stat(name string) string {
+	return fmt.Sprintf("election.%s.%s", e.name, name)
+}
@@ -0,0 +1,140 @@
+package election_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/backoff"
+	"github.com/graymeta/gmkit/lock"
+	"github.com/graymeta/gmkit/lock/election"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElector(t *testing.T) {
+	const name = "singleton"
+	const ttl = 50 * time.Millisecond
+
+	fastBackoff := election.AcquireOpts(lock.AcquireBackoff(backoff.New(
+		backoff.InitBackoff(time.Millisecond),
+		backoff.MaxBackoff(5*time.Millisecond),
+		backoff.MaxCalls(0),
+	)))
+
+	type candidate struct {
+		*election.Elector
+		elected chan string
+		demoted chan string
+		cancel  context.CancelFunc
+		done    chan error
+	}
+
+	start := func(l lock.Locker, id string) *candidate {
+		c := &candidate{
+			elected: make(chan string, 10),
+			demoted: make(chan string, 10),
+			done:    make(chan error, 1),
+		}
+		c.Elector = election.New(l, name, id,
+			election.TTL(ttl),
+			fastBackoff,
+			election.OnElected(func(ctx context.Context) {
+				c.elected <- id
+				<-ctx.Done()
+			}),
+			election.OnDemoted(func() { c.demoted <- id }),
+		)
+
+		var ctx context.Context
+		ctx, c.cancel = context.WithCancel(context.Background())
+		go func() { c.done <- c.Run(ctx) }()
+		return c
+	}
+
+	receive := func(t *testing.T, ch chan string) string {
+		t.Helper()
+		select {
+		case id := <-ch:
+			return id
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+			return ""
+		}
+	}
+
+	t.Run("steps down on shutdown", func(t *testing.T) {
+		l := lock.NewMemoryLocker()
+
+		c1 := start(l, "c1")
+		require.Equal(t, "c1", receive(t, c1.elected))
+		assert.True(t, c1.IsLeader())
+
+		c2 := start(l, "c2")
+		defer c2.cancel()
+
+		// c2 stays a follower while c1 refreshes the lock
+		time.Sleep(3 * ttl)
+		assert.False(t, c2.IsLeader())
+		assert.Empty(t, c2.elected)
+		for _, c := range []*candidate{c1, c2} {
+			leader, err := c.Leader()
+			require.NoError(t, err)
+			assert.Equal(t, "c1", leader)
+		}
+
+		c1.cancel()
+		require.NoError(t, <-c1.done)
+		assert.Equal(t, "c1", receive(t, c1.demoted))
+		assert.False(t, c1.IsLeader())
+
+		require.Equal(t, "c2", receive(t, c2.elected))
+		leader, err := c1.Leader()
+		require.NoError(t, err)
+		assert.Equal(t, "c2", leader)
+	})
+
+	t.Run("demoted when the lock is lost", func(t *testing.T) {
+		l := lock.NewMemoryLocker()
+
+		c1 := start(l, "c1")
+		defer c1.cancel()
+		require.Equal(t, "c1", receive(t, c1.elected))
+
+		// another holder takes the lock
+		require.NoError(t, l.Unlock(name, "c1"))
+		acquired, err := l.Lock(name, "other", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		assert.Equal(t, "c1", receive(t, c1.demoted))
+		assert.False(t, c1.IsLeader())
+
+		leader, err := c1.Leader()
+		require.NoError(t, err)
+		assert.Equal(t, "other", leader)
+
+		// c1 campaigns again and is elected once the lock is free
+		require.NoError(t, l.Unlock(name, "other"))
+		assert.Equal(t, "c1", receive(t, c1.elected))
+	})
+
+	t.Run("leader unknown without an owner locker", func(t *testing.T) {
+		l := struct{ lock.Locker }{lock.NewMemoryLocker()}
+
+		c1 := start(l, "c1")
+		defer c1.cancel()
+		require.Equal(t, "c1", receive(t, c1.elected))
+
+		c2 := start(l, "c2")
+		defer c2.cancel()
+
+		leader, err := c1.Leader()
+		require.NoError(t, err)
+		assert.Equal(t, "c1", leader)
+
+		_, err = c2.Leader()
+		assert.Equal(t, election.ErrLeaderUnknown, err)
+	})
+}
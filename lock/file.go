@@ -0,0 +1,233 @@
+package lock
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// tokenFile is the file in the directory of a FileLocker holding the last
+// fencing token issued. Lock files end in .lock, so it cannot be a lock file.
+const tokenFile = "fencing.token"
+
+// FileLocker is a Locker implementation for coordinating processes on a single
+// host. Each held lock is a file in a directory holding the owner, expiry and
+// fencing token of the lock, which is read and written while holding an
+// exclusive flock on the file. The file is removed when the lock is unlocked,
+// and expired lock files are removed periodically. Locks expire and are owned
+// the same way as with RedisLocker. FileLocker is only supported on Linux.
+type FileLocker struct {
+	dir   string
+	calls int64
+}
+
+var _ (FencingLocker) = (*FileLocker)(nil)
+
+// NewFileLocker initializes a new FileLocker that keeps lock files in dir. The
+// directory is created if it does not exist.
+func NewFileLocker(dir string) (*FileLocker, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileLocker{dir: dir}, nil
+}
+
+// Lock attempts to acquire the lock. Returns true if the lock was acquired.
+func (l *FileLocker) Lock(name, uniqueID string, duration time.Duration) (bool, error) {
+	acquired, _, err := l.LockWithToken(name, uniqueID, duration)
+	return acquired, err
+}
+
+// LockWithToken attempts to acquire the lock. Returns true and the fencing token
+// if the lock was acquired. The token is incremented each time the lock is
+// newly acquired.
+func (l *FileLocker) LockWithToken(name, uniqueID string, duration time.Duration) (bool, int64, error) {
+	var (
+		acquired bool
+		token    int64
+	)
+	if atomic.AddInt64(&l.calls, 1)%sweepEvery == 0 {
+		if err := l.sweep(); err != nil {
+			return false, 0, err
+		}
+	}
+
+	err := l.withFile(l.path(name), func(current fileLock) (*fileLock, error) {
+		now := time.Now()
+		held := now.Before(current.expires)
+		if held && current.owner != uniqueID {
+			return nil, nil
+		}
+
+		acquired, token = true, current.token
+		if !held {
+			var err error
+			if token, err = l.nextToken(current.token); err != nil {
+				return nil, err
+			}
+		}
+		return &fileLock{
+			owner:   uniqueID,
+			expires: now.Add(duration),
+			token:   token,
+		}, nil
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return acquired, token, nil
+}
+
+// Unlock attempts to unlock a lock, but only if the uniqueID is the one that has the lock.
+func (l *FileLocker) Unlock(name, uniqueID string) error {
+	return l.withFile(l.path(name), func(current fileLock) (*fileLock, error) {
+		if current.owner != uniqueID || !time.Now().Before(current.expires) {
+			return nil, ErrUnlock
+		}
+		return &fileLock{}, nil
+	})
+}
+
+// sweep removes the files of expired locks.
+func (l *FileLocker) sweep() error {
+	paths, err := filepath.Glob(filepath.Join(l.dir, "*.lock"))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, path := range paths {
+		err := l.withFile(path, func(current fileLock) (*fileLock, error) {
+			if now.Before(current.expires) {
+				return nil, nil
+			}
+			return &fileLock{}, nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextToken issues a fencing token newer than both the last token issued and
+// min, the token of the last hold on a lock.
+func (l *FileLocker) nextToken(min int64) (int64, error) {
+	var token int64
+	err := l.withFile(filepath.Join(l.dir, tokenFile), func(current fileLock) (*fileLock, error) {
+		token = current.token
+		if min > token {
+			token = min
+		}
+		token++
+		// the token file never expires, so it is never removed.
+		return &fileLock{owner: tokenFile, token: token}, nil
+	})
+	return token, err
+}
+
+func (l *FileLocker) path(name string) string {
+	return filepath.Join(l.dir, url.PathEscape(name)+".lock")
+}
+
+type fileLock struct {
+	owner   string
+	expires time.Time
+	token   int64
+}
+
+// withFile calls fn with the current state of the lock while holding an
+// exclusive flock on its file. The state returned by fn, if any, is written to
+// the file before the flock is released, or the file is removed if the state
+// has no owner.
+func (l *FileLocker) withFile(path string, fn func(fileLock) (*fileLock, error)) error {
+	f, err := openLocked(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer funlock(f)
+
+	var current fileLock
+	var expires int64
+	_, err = fmt.Fscanf(bufio.NewReader(f), "%q %d %d\n", &current.owner, &expires, &current.token)
+	if err != nil && err != io.EOF {
+		return errors.Wrapf(err, "reading lock file %s", path)
+	}
+	if expires > 0 {
+		current.expires = time.Unix(0, expires)
+	}
+
+	next, err := fn(current)
+	if next == nil && current.owner == "" {
+		// the file was created by opening it, and holds no lock.
+		next = &fileLock{}
+	}
+	if next == nil {
+		return err
+	}
+	if next.owner == "" {
+		// removed while holding the flock, so that processes waiting on the
+		// flock see the file was removed, and open the path again.
+		if rmErr := os.Remove(path); err == nil {
+			err = rmErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var nextExpires int64
+	if !next.expires.IsZero() {
+		nextExpires = next.expires.UnixNano()
+	}
+	_, err = fmt.Fprintf(f, "%q %d %d\n", next.owner, nextExpires, next.token)
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// openLocked opens the file at path, creating it if it does not exist, and
+// takes an exclusive flock on it. The file may be removed by another process
+// between opening it and taking the flock, in which case the path is opened
+// again.
+func openLocked(path string) (*os.File, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := flock(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		opened, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		current, err := os.Stat(path)
+		if err == nil && os.SameFile(opened, current) {
+			return f, nil
+		}
+		f.Close()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package lock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLocker(t *testing.T) {
+	t.Run("lockers sharing a directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		var wg sync.WaitGroup
+		acquired := make(chan string, 10)
+		for i := 0; i < cap(acquired); i++ {
+			l, err := NewFileLocker(dir)
+			require.NoError(t, err)
+
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				ok, err := l.Lock("foo/bar", id, time.Minute)
+				assert.NoError(t, err)
+				if ok {
+					acquired <- id
+				}
+			}(string(rune('a' + i)))
+		}
+		wg.Wait()
+		close(acquired)
+
+		require.Len(t, acquired, 1)
+	})
+}
+
+func TestFileLockerRemovesFiles(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewFileLocker(dir)
+	require.NoError(t, err)
+
+	lockFiles := func() []string {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.lock"))
+		require.NoError(t, err)
+		return paths
+	}
+
+	ok, first, err := l.LockWithToken("foo", "host1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, lockFiles(), 1)
+
+	require.NoError(t, l.Unlock("foo", "host1"))
+	require.Empty(t, lockFiles())
+
+	// a failed unlock does not leave a file behind.
+	require.Equal(t, ErrUnlock, l.Unlock("bar", "host1"))
+	require.Empty(t, lockFiles())
+
+	// tokens keep increasing after the file of a lock is removed.
+	ok, second, err := l.LockWithToken("foo", "host1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Greater(t, second, first)
+	require.NoError(t, l.Unlock("foo", "host1"))
+
+	// the files of expired locks are removed periodically.
+	for i := 0; i < 10*sweepEvery; i++ {
+		ok, err := l.Lock(fmt.Sprintf("foo:%d", i), "host1", time.Nanosecond)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.True(t, len(lockFiles()) <= sweepEvery, "%d lock files", len(lockFiles()))
+
+	_, err = os.Stat(filepath.Join(dir, tokenFile))
+	require.NoError(t, err)
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package lock
+
+import (
+	"errors"
+	"os"
+)
+
+var errFlockUnsupported = errors.New("file locks are only supported on linux")
+
+func flock(*os.File) error {
+	return errFlockUnsupported
+}
+
+func funlock(*os.File) error {
+	return errFlockUnsupported
+}
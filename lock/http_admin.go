@@ -0,0 +1,121 @@
+package lock
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// Admin is an HTTP handler that lets operators inspect the locks held in an
+// InspectLocker and force unlock stuck locks. It serves:
+//
+//	GET    /?prefix={prefix}  lists the held locks whose names start with prefix
+//	GET    /{name}            describes a held lock
+//	DELETE /{name}            force unlocks a lock
+//
+// Admin performs no authorization, mount it behind the appropriate middleware.
+// An Admin must be initialized with NewAdmin.
+type Admin struct {
+	locker InspectLocker
+	router *mux.Router
+}
+
+// NewAdmin initializes a new Admin for the locker.
+func NewAdmin(l InspectLocker) *Admin {
+	a := &Admin{locker: l}
+	a.router = mux.NewRouter()
+	a.router.HandleFunc("/", a.list).Methods(http.MethodGet)
+	a.router.HandleFunc("/{name:.+}", a.get).Methods(http.MethodGet)
+	a.router.HandleFunc("/{name:.+}", a.forceUnlock).Methods(http.MethodDelete)
+	return a
+}
+
+type adminLock struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+	// TTLMillis is -1 if the lock never expires.
+	TTLMillis int64 `json:"ttl_ms"`
+}
+
+func newAdminLock(info Info) adminLock {
+	ttl := int64(info.TTL / time.Millisecond)
+	if info.TTL < 0 {
+		ttl = -1
+	}
+	return adminLock{Name: info.Name, Owner: info.Owner, TTLMillis: ttl}
+}
+
+// ServeHTTP serves up the request.
+func (a *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "" {
+		r.URL.Path = "/"
+	}
+	a.router.ServeHTTP(w, r)
+}
+
+func (a *Admin) list(w http.ResponseWriter, r *http.Request) {
+	infos, err := a.locker.List(r.URL.Query().Get("prefix"))
+	if err != nil {
+		log.Println(errors.Wrap(err, "listing locks"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	locks := make([]adminLock, 0, len(infos))
+	for _, info := range infos {
+		locks = append(locks, newAdminLock(info))
+	}
+	writeJSON(w, http.StatusOK, locks)
+}
+
+func (a *Admin) get(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	owner, err := a.locker.Owner(name)
+	if err != nil {
+		log.Println(errors.Wrap(err, "getting lock owner"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if owner == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	ttl, err := a.locker.TTL(name)
+	if err != nil {
+		log.Println(errors.Wrap(err, "getting lock ttl"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAdminLock(Info{Name: name, Owner: owner, TTL: ttl}))
+}
+
+func (a *Admin) forceUnlock(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	err := a.locker.ForceUnlock(name)
+	if err == ErrUnlock {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(errors.Wrap(err, "force unlocking lock"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	log.Printf("lock %q force unlocked", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(errors.Wrap(err, "encoding response"))
+	}
+}
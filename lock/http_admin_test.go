@@ -0,0 +1,85 @@
+package lock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin(t *testing.T) {
+	l := NewMemoryLocker()
+	for _, name := range []string{"jobs/a", "jobs/b", "other"} {
+		acquired, err := l.Lock(name, "host1", time.Minute)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	}
+
+	srv := httptest.NewServer(http.StripPrefix("/locks", NewAdmin(l)))
+	defer srv.Close()
+
+	do := func(t *testing.T, method, path string, v interface{}) int {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if v != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+		}
+		return resp.StatusCode
+	}
+
+	t.Run("list", func(t *testing.T) {
+		var locks []adminLock
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/locks?prefix=jobs/", &locks))
+		require.Len(t, locks, 2)
+		assert.Equal(t, "jobs/a", locks[0].Name)
+		assert.Equal(t, "host1", locks[0].Owner)
+		assert.True(t, locks[0].TTLMillis > 0)
+		assert.Equal(t, "jobs/b", locks[1].Name)
+
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/locks", &locks))
+		assert.Len(t, locks, 3)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		var lock adminLock
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/locks/jobs/a", &lock))
+		assert.Equal(t, "jobs/a", lock.Name)
+		assert.Equal(t, "host1", lock.Owner)
+		assert.True(t, lock.TTLMillis > 0 && lock.TTLMillis <= int64(time.Minute/time.Millisecond))
+
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodGet, "/locks/missing", nil))
+	})
+
+	t.Run("force unlock", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, do(t, http.MethodDelete, "/locks/jobs/a", nil))
+		assert.Equal(t, http.StatusNotFound, do(t, http.MethodDelete, "/locks/jobs/a", nil))
+
+		acquired, err := l.Lock("jobs/a", "host2", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+	})
+}
+
+func TestAdminConcurrentRequests(t *testing.T) {
+	admin := NewAdmin(NewMemoryLocker())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,235 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/graymeta/gmkit/backoff"
+)
+
+var (
+	// ErrLockLost is the error returned when a lock that was held has been
+	// acquired by another unique id.
+	ErrLockLost = errors.New("lock lost, acquired by another unique id")
+
+	// ErrNotAcquired is the error returned when a lock could not be acquired
+	// before the acquire backoff gave up.
+	ErrNotAcquired = errors.New("lock not acquired")
+)
+
+// noRetry wraps an error so that a backoff does not retry it.
+type noRetry struct {
+	error
+}
+
+func (noRetry) Retry() bool { return false }
+
+func (n noRetry) Unwrap() error { return n.error }
+
+// AcquireOptFn is a functional option for Acquire.
+type AcquireOptFn func(o *acquireOpts)
+
+type acquireOpts struct {
+	acquireBackoff backoff.Backoffer
+	refreshBackoff backoff.Backoffer
+	refreshPeriod  time.Duration
+}
+
+// AcquireBackoff sets the backoff used while waiting for the lock to become
+// available. Defaults to a jittered backoff that never gives up, unless the ttl
+// is too short to wait a tenth of, in which case the lock is tried once.
+func AcquireBackoff(b backoff.Backoffer) AcquireOptFn {
+	return func(o *acquireOpts) {
+		o.acquireBackoff = b
+	}
+}
+
+// RefreshBackoff sets the backoff used for each refresh of the lock. The lease
+// is considered lost if the backoff gives up. Defaults to a few jittered
+// attempts that give up well before the lock expires, so that a transient error
+// does not lose the lease.
+func RefreshBackoff(b backoff.Backoffer) AcquireOptFn {
+	return func(o *acquireOpts) {
+		o.refreshBackoff = b
+	}
+}
+
+// RefreshPeriod sets how often the lock is refreshed. Defaults to a third of the
+// lock's ttl.
+func RefreshPeriod(d time.Duration) AcquireOptFn {
+	return func(o *acquireOpts) {
+		o.refreshPeriod = d
+	}
+}
+
+// acquireBackoff returns the default acquire backoff, which waits from a tenth
+// of the ttl up to the ttl between attempts.
+func acquireBackoff(ttl time.Duration) backoff.Backoffer {
+	if ttl/10 <= 0 {
+		return backoff.NoopBackoff{}
+	}
+	return backoff.New(
+		backoff.InitBackoff(ttl/10),
+		backoff.MaxBackoff(ttl),
+		backoff.MaxCalls(0),
+		backoff.Jitter(),
+	)
+}
+
+// refreshBackoff returns the default refresh backoff, which retries for about
+// half of the time left before the lock expires, the ttl less the refresh
+// period, leaving time for the attempts themselves.
+func refreshBackoff(left time.Duration) backoff.Backoffer {
+	if left/10 <= 0 {
+		return backoff.NoopBackoff{}
+	}
+	return backoff.New(
+		backoff.InitBackoff(left/10),
+		backoff.MaxBackoff(left/5),
+		backoff.MaxCalls(4),
+		backoff.Jitter(),
+	)
+}
+
+// Lease is a held lock that is refreshed in the background until it is
+// released or lost.
+type Lease struct {
+	locker *UniqueLocker
+	name   string
+	token  int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	lost   chan struct{}
+	done   chan struct{}
+
+	mu      sync.Mutex
+	err     error
+	release sync.Once
+}
+
+// Acquire blocks until the lock identified by name is acquired or the context
+// is cancelled. Once acquired, the lock is refreshed in the background until the
+// returned Lease is released, the lock is lost, or the context is cancelled.
+func (l *UniqueLocker) Acquire(ctx context.Context, name string, ttl time.Duration, opts ...AcquireOptFn) (*Lease, error) {
+	o := acquireOpts{
+		refreshPeriod: ttl / 3,
+	}
+	for _, fn := range opts {
+		fn(&o)
+	}
+	if o.acquireBackoff == nil {
+		o.acquireBackoff = acquireBackoff(ttl)
+	}
+	if o.refreshBackoff == nil {
+		o.refreshBackoff = refreshBackoff(ttl - o.refreshPeriod)
+	}
+
+	var token int64
+	err := o.acquireBackoff.BackoffCtx(ctx, func(context.Context) error {
+		acquired, t, err := l.lockWithToken(name, ttl)
+		if err != nil {
+			return err
+		}
+		if !acquired {
+			return ErrNotAcquired
+		}
+		token = t
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	leaseCtx, cancel := context.WithCancel(ctx)
+	lease := &Lease{
+		locker: l,
+		name:   name,
+		token:  token,
+		ctx:    leaseCtx,
+		cancel: cancel,
+		lost:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(lease.done)
+		err := l.refresh(leaseCtx, o.refreshBackoff, name, ttl, o.refreshPeriod, token)
+		if err == nil {
+			return
+		}
+
+		lease.mu.Lock()
+		lease.err = err
+		lease.mu.Unlock()
+		close(lease.lost)
+		cancel()
+	}()
+
+	return lease, nil
+}
+
+// Name returns the name of the lock held by the lease.
+func (l *Lease) Name() string {
+	return l.name
+}
+
+// Token returns the fencing token issued when the lock was acquired, or 0 if the
+// underlying Locker is not a FencingLocker.
+func (l *Lease) Token() int64 {
+	return l.token
+}
+
+// Lost returns a channel that is closed when the lock has been lost, either
+// because it was acquired by another unique id or because it could not be
+// refreshed.
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Context returns a context that is cancelled when the lease is lost or
+// released, or when the context provided to Acquire is cancelled.
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Err returns the reason the lease was lost, or nil if it has not been lost.
+func (l *Lease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Release stops refreshing the lock and unlocks it. Releasing a lease that was
+// lost to another unique id is a noop. A lease lost because it could not be
+// refreshed may still be held, so it is unlocked, ignoring ErrUnlock if it is
+// not. Only the first call to Release has any effect.
+func (l *Lease) Release() error {
+	var err error
+	l.release.Do(func() {
+		l.cancel()
+		<-l.done
+
+		lostErr := l.Err()
+		if lostErr == ErrLockLost {
+			return
+		}
+		err = l.locker.Unlock(l.name)
+		if lostErr != nil && err == ErrUnlock {
+			err = nil
+		}
+	})
+	return err
+}
+
+// lockWithToken acquires the lock, returning the fencing token when the
+// underlying Locker supports it.
+func (l *UniqueLocker) lockWithToken(name string, ttl time.Duration) (bool, int64, error) {
+	acquired, token, err := l.LockWithToken(name, ttl)
+	if err == ErrFencingUnsupported {
+		acquired, err = l.Lock(name, ttl)
+	}
+	return acquired, token, err
+}
@@ -0,0 +1,299 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/backoff"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueLocker_Acquire(t *testing.T) {
+	const name = "foo"
+	const ttl = 30 * time.Millisecond
+
+	fastBackoff := AcquireBackoff(backoff.New(
+		backoff.InitBackoff(time.Millisecond),
+		backoff.MaxBackoff(time.Millisecond),
+		backoff.MaxCalls(0),
+	))
+
+	newLocker := func(owner *string, mu *sync.Mutex) *LockerMock {
+		return &LockerMock{
+			LockFunc: func(name string, uniqueID string, duration time.Duration) (bool, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if *owner == "" || *owner == uniqueID {
+					*owner = uniqueID
+					return true, nil
+				}
+				return false, nil
+			},
+			UnlockFunc: func(name string, uniqueID string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if *owner != uniqueID {
+					return ErrUnlock
+				}
+				*owner = ""
+				return nil
+			},
+		}
+	}
+
+	t.Run("acquires once available and releases", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := "l2"
+		l := newLocker(&owner, &mu)
+
+		time.AfterFunc(10*time.Millisecond, func() {
+			require.NoError(t, l.Unlock(name, "l2"))
+		})
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl, fastBackoff)
+		require.NoError(t, err)
+		assert.Equal(t, name, lease.Name())
+		assert.Greater(t, len(l.LockCalls()), 1)
+
+		time.Sleep(2 * ttl)
+		select {
+		case <-lease.Lost():
+			t.Fatal("lease should not be lost")
+		default:
+		}
+
+		require.NoError(t, lease.Release())
+		require.NoError(t, lease.Release())
+		assert.Error(t, lease.Context().Err())
+		assert.Len(t, l.UnlockCalls(), 2)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Empty(t, owner)
+	})
+
+	t.Run("context cancelled before acquiring", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := "l2"
+		l := newLocker(&owner, &mu)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := NewUniqueLocker(l, "l1").Acquire(ctx, name, ttl, fastBackoff)
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("acquire backoff gives up", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := "l2"
+		l := newLocker(&owner, &mu)
+
+		_, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl, AcquireBackoff(backoff.NoopBackoff{}))
+		require.Equal(t, ErrNotAcquired, err)
+	})
+
+	t.Run("lost when taken by another holder", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := ""
+		l := newLocker(&owner, &mu)
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl, fastBackoff)
+		require.NoError(t, err)
+
+		mu.Lock()
+		owner = "l2"
+		mu.Unlock()
+
+		select {
+		case <-lease.Lost():
+		case <-time.After(time.Second):
+			t.Fatal("lease should have been lost")
+		}
+		assert.Equal(t, ErrLockLost, lease.Err())
+		assert.Error(t, lease.Context().Err())
+
+		require.NoError(t, lease.Release())
+		assert.Empty(t, l.UnlockCalls())
+	})
+
+	t.Run("lost when refresh fails", func(t *testing.T) {
+		refreshErr := errors.New("connection refused")
+		var calls int
+		l := &LockerMock{
+			LockFunc: func(name string, uniqueID string, duration time.Duration) (bool, error) {
+				calls++
+				if calls > 1 {
+					return false, refreshErr
+				}
+				return true, nil
+			},
+		}
+
+		l.UnlockFunc = func(name string, uniqueID string) error {
+			return ErrUnlock
+		}
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl, RefreshPeriod(time.Millisecond))
+		require.NoError(t, err)
+
+		select {
+		case <-lease.Context().Done():
+		case <-time.After(time.Second):
+			t.Fatal("lease should have been lost")
+		}
+		assert.Equal(t, refreshErr, lease.Err())
+
+		// the lock may still be held, so it is unlocked.
+		require.NoError(t, lease.Release())
+		assert.Len(t, l.UnlockCalls(), 1)
+	})
+
+	t.Run("retries a transient refresh error", func(t *testing.T) {
+		var mu sync.Mutex
+		var calls int
+		l := &LockerMock{
+			LockFunc: func(name string, uniqueID string, duration time.Duration) (bool, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls++
+				if calls == 2 {
+					return false, errors.New("connection reset")
+				}
+				return true, nil
+			},
+			UnlockFunc: func(name string, uniqueID string) error {
+				return nil
+			},
+		}
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl)
+		require.NoError(t, err)
+
+		time.Sleep(3 * ttl)
+		select {
+		case <-lease.Lost():
+			t.Fatalf("lease should not be lost: %v", lease.Err())
+		default:
+		}
+		require.NoError(t, lease.Release())
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Greater(t, calls, 3)
+	})
+
+	t.Run("ttl too short to back off", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := "l2"
+		l := newLocker(&owner, &mu)
+
+		_, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, 5*time.Nanosecond)
+		require.Equal(t, ErrNotAcquired, err)
+	})
+
+	t.Run("lost when acquired anew after expiring", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := ""
+		l := &fencingLocker{LockerMock: newLocker(&owner, &mu), token: 1}
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl)
+		require.NoError(t, err)
+
+		// another holder acquired and released the lock while it was expired.
+		atomic.StoreInt64(&l.token, 2)
+
+		select {
+		case <-lease.Lost():
+		case <-time.After(time.Second):
+			t.Fatal("lease should have been lost")
+		}
+		assert.Equal(t, ErrLockLost, lease.Err())
+		assert.Equal(t, int64(1), lease.Token())
+
+		// the new hold is released rather than kept for the lost lease.
+		require.NoError(t, lease.Release())
+		assert.Len(t, l.UnlockCalls(), 1)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Empty(t, owner)
+	})
+
+	t.Run("lost when an extend fails", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := ""
+		l := extendLocker{LockerMock: newLocker(&owner, &mu), owner: &owner, mu: &mu}
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl)
+		require.NoError(t, err)
+
+		// the lock expired, so it cannot be extended.
+		mu.Lock()
+		owner = ""
+		mu.Unlock()
+
+		select {
+		case <-lease.Lost():
+		case <-time.After(time.Second):
+			t.Fatal("lease should have been lost")
+		}
+		assert.Equal(t, ErrLockLost, lease.Err())
+		assert.Len(t, l.LockCalls(), 1)
+	})
+
+	t.Run("token from fencing locker", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := ""
+		l := &fencingLocker{LockerMock: newLocker(&owner, &mu), token: 42}
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl)
+		require.NoError(t, err)
+		defer lease.Release()
+		assert.Equal(t, int64(42), lease.Token())
+	})
+
+	t.Run("no token without fencing locker", func(t *testing.T) {
+		var mu sync.Mutex
+		owner := ""
+		l := newLocker(&owner, &mu)
+
+		_, _, err := NewUniqueLocker(l, "l1").LockWithToken(name, ttl)
+		require.Equal(t, ErrFencingUnsupported, err)
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, ttl)
+		require.NoError(t, err)
+		defer lease.Release()
+		assert.Zero(t, lease.Token())
+	})
+}
+
+type fencingLocker struct {
+	*LockerMock
+	token int64
+}
+
+func (f *fencingLocker) LockWithToken(name, uniqueID string, duration time.Duration) (bool, int64, error) {
+	acquired, err := f.Lock(name, uniqueID, duration)
+	if !acquired || err != nil {
+		return false, 0, err
+	}
+	return true, atomic.LoadInt64(&f.token), nil
+}
+
+type extendLocker struct {
+	*LockerMock
+	owner *string
+	mu    *sync.Mutex
+}
+
+func (e extendLocker) Extend(name, uniqueID string, duration time.Duration) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return *e.owner == uniqueID, nil
+}
@@ -13,3 +13,61 @@ type Locker interface {
 	// has the lock.
 	Unlock(name, uniqueID string) error
 }
+
+// FencingLocker is a Locker that issues a fencing token each time a lock is
+// acquired. Tokens for a given lock name increase monotonically, so a resource
+// guarded by the lock can reject writes that carry a token older than the
+// newest one it has seen.
+type FencingLocker interface {
+	Locker
+
+	// LockWithToken behaves as Lock, additionally returning the fencing token
+	// of the current hold on the lock when it has been acquired. Refreshing a
+	// lock that is already held by uniqueID returns the same token.
+	LockWithToken(name, uniqueID string, duration time.Duration) (acquired bool, token int64, err error)
+}
+
+// ExtendLocker is a Locker that can extend a lock it holds without acquiring it
+// anew, so that a lock that expired between refreshes, and may have been held
+// by another unique id since, is found to be lost.
+type ExtendLocker interface {
+	Locker
+
+	// Extend resets the expiry of the lock identified by name to duration, but
+	// only if it is held by uniqueID. Returns false if it is not.
+	Extend(name, uniqueID string, duration time.Duration) (bool, error)
+}
+
+// OwnerLocker is a Locker that can report which unique id holds a lock.
+type OwnerLocker interface {
+	Locker
+
+	// Owner returns the unique id holding the lock identified by name, or an
+	// empty string if the lock is not held.
+	Owner(name string) (string, error)
+}
+
+// InspectLocker is a Locker that can list and break the locks it holds, so that
+// operators can diagnose stuck locks.
+type InspectLocker interface {
+	OwnerLocker
+
+	// TTL returns the time remaining before the lock identified by name
+	// expires, 0 if the lock is not held, or a negative duration if the lock
+	// never expires.
+	TTL(name string) (time.Duration, error)
+
+	// List returns the locks currently held whose names start with prefix.
+	List(prefix string) ([]Info, error)
+
+	// ForceUnlock unlocks a lock regardless of the unique id holding it.
+	// Returns ErrUnlock if the lock is not held.
+	ForceUnlock(name string) error
+}
+
+// Info describes a held lock.
+type Info struct {
+	Name  string
+	Owner string
+	TTL   time.Duration
+}
@@ -0,0 +1,209 @@
+// Package locktest provides a conformance suite for implementations of
+// lock.Locker.
+package locktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/lock"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLocker runs a conformance suite against a Locker, checking it expires and
+// owns locks the same way as lock.RedisLocker. newLocker is called for each
+// subtest and must return a Locker that holds no locks. If the Locker is a
+// lock.FencingLocker, its fencing tokens are checked as well.
+func TestLocker(t *testing.T, newLocker func(t *testing.T) lock.Locker) {
+	const expiration = 200 * time.Millisecond
+	const name = "foo"
+
+	t.Run("lock expiration", func(t *testing.T) {
+		l := newLocker(t)
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		time.Sleep(100*time.Millisecond + expiration)
+
+		// should be able to get the lock now
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("refresh lock", func(t *testing.T) {
+		l := newLocker(t)
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		// set the lock again, this time 3x the expiration
+		result, err = l.Lock(name, "host1", 3*expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		time.Sleep(100*time.Millisecond + expiration)
+
+		// should not be able to get the lock
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		// should be able to get the lock now
+		time.Sleep(2 * expiration)
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("unlock", func(t *testing.T) {
+		l := newLocker(t)
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		// a host2 unlock should fail
+		require.Equal(t, lock.ErrUnlock, l.Unlock(name, "host2"))
+
+		// host1 should be able to unlock it, but only once
+		require.NoError(t, l.Unlock(name, "host1"))
+		require.Equal(t, lock.ErrUnlock, l.Unlock(name, "host1"))
+
+		// host2 should be able to immediately acquire the lock
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("unlock expired lock", func(t *testing.T) {
+		l := newLocker(t)
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		time.Sleep(100*time.Millisecond + expiration)
+		require.Equal(t, lock.ErrUnlock, l.Unlock(name, "host1"))
+	})
+
+	t.Run("names are independent", func(t *testing.T) {
+		l := newLocker(t)
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		result, err = l.Lock("bar", "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		require.Equal(t, lock.ErrUnlock, l.Unlock("bar", "host1"))
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		ol, ok := newLocker(t).(lock.OwnerLocker)
+		if !ok {
+			t.Skip("locker does not report owners")
+		}
+
+		owner, err := ol.Owner(name)
+		require.NoError(t, err)
+		require.Empty(t, owner)
+
+		result, err := ol.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		owner, err = ol.Owner(name)
+		require.NoError(t, err)
+		require.Equal(t, "host1", owner)
+
+		time.Sleep(100*time.Millisecond + expiration)
+		owner, err = ol.Owner(name)
+		require.NoError(t, err)
+		require.Empty(t, owner)
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		il, ok := newLocker(t).(lock.InspectLocker)
+		if !ok {
+			t.Skip("locker does not support inspection")
+		}
+
+		ttl, err := il.TTL(name)
+		require.NoError(t, err)
+		require.Zero(t, ttl)
+
+		for _, n := range []string{"jobs:a", "jobs:b", "other"} {
+			result, err := il.Lock(n, "host1", expiration)
+			require.NoError(t, err)
+			require.True(t, result)
+		}
+
+		ttl, err = il.TTL("jobs:a")
+		require.NoError(t, err)
+		require.True(t, ttl > 0 && ttl <= expiration, "ttl %s", ttl)
+
+		infos, err := il.List("jobs:")
+		require.NoError(t, err)
+		require.Len(t, infos, 2)
+		names := map[string]bool{}
+		for _, info := range infos {
+			names[info.Name] = true
+			require.Equal(t, "host1", info.Owner)
+			require.True(t, info.TTL > 0 && info.TTL <= expiration, "ttl %s", info.TTL)
+		}
+		require.Equal(t, map[string]bool{"jobs:a": true, "jobs:b": true}, names)
+
+		require.NoError(t, il.ForceUnlock("jobs:a"))
+		require.Equal(t, lock.ErrUnlock, il.ForceUnlock("jobs:a"))
+
+		result, err := il.Lock("jobs:a", "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("fencing tokens", func(t *testing.T) {
+		fl, ok := newLocker(t).(lock.FencingLocker)
+		if !ok {
+			t.Skip("locker does not issue fencing tokens")
+		}
+
+		result, first, err := fl.LockWithToken(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		// refreshing keeps the same token
+		result, token, err := fl.LockWithToken(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Equal(t, first, token)
+
+		result, _, err = fl.LockWithToken(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		// a new hold on the lock issues a newer token, after an unlock
+		require.NoError(t, fl.Unlock(name, "host1"))
+		result, second, err := fl.LockWithToken(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Greater(t, second, first)
+
+		// or after the lock expires, even for the same unique id
+		time.Sleep(100*time.Millisecond + expiration)
+		result, third, err := fl.LockWithToken(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Greater(t, third, second)
+	})
+}
@@ -0,0 +1,167 @@
+package lock
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many calls to LockWithToken are made between removals of
+// expired locks from a MemoryLocker.
+const sweepEvery = 64
+
+// MemoryLocker is an in memory Locker implementation for tests and single
+// process use. Locks expire and are owned the same way as with RedisLocker.
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+	calls int
+
+	// token is the last fencing token issued. It is shared by every lock, so
+	// that expired locks can be removed without reissuing their tokens.
+	token int64
+}
+
+type memoryLock struct {
+	owner   string
+	expires time.Time
+	token   int64
+}
+
+var (
+	_ (FencingLocker) = (*MemoryLocker)(nil)
+	_ (InspectLocker) = (*MemoryLocker)(nil)
+)
+
+// NewMemoryLocker initializes a new MemoryLocker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		locks: make(map[string]memoryLock),
+	}
+}
+
+// Lock attempts to acquire the lock. Returns true if the lock was acquired.
+func (l *MemoryLocker) Lock(name, uniqueID string, duration time.Duration) (bool, error) {
+	acquired, _, err := l.LockWithToken(name, uniqueID, duration)
+	return acquired, err
+}
+
+// LockWithToken attempts to acquire the lock. Returns true and the fencing token
+// if the lock was acquired. A newer token is issued each time the lock is newly
+// acquired.
+func (l *MemoryLocker) LockWithToken(name, uniqueID string, duration time.Duration) (bool, int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	current, held := l.locks[name]
+	held = held && now.Before(current.expires)
+	if held && current.owner != uniqueID {
+		return false, 0, nil
+	}
+	token := current.token
+	if !held {
+		l.token++
+		token = l.token
+	}
+
+	l.locks[name] = memoryLock{
+		owner:   uniqueID,
+		expires: now.Add(duration),
+		token:   token,
+	}
+	return true, token, nil
+}
+
+// sweep removes expired locks every sweepEvery calls.
+func (l *MemoryLocker) sweep(now time.Time) {
+	l.calls++
+	if l.calls < sweepEvery {
+		return
+	}
+	l.calls = 0
+	for name, current := range l.locks {
+		if !now.Before(current.expires) {
+			delete(l.locks, name)
+		}
+	}
+}
+
+// Unlock attempts to unlock a lock, but only if the uniqueID is the one that has the lock.
+func (l *MemoryLocker) Unlock(name, uniqueID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, ok := l.locks[name]
+	if !ok || current.owner != uniqueID || !time.Now().Before(current.expires) {
+		return ErrUnlock
+	}
+	delete(l.locks, name)
+	return nil
+}
+
+// Owner returns the unique id holding the lock, or an empty string if the lock
+// is not held.
+func (l *MemoryLocker) Owner(name string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, ok := l.locks[name]
+	if !ok || !time.Now().Before(current.expires) {
+		return "", nil
+	}
+	return current.owner, nil
+}
+
+// TTL returns the time remaining before the lock expires, or 0 if the lock is
+// not held.
+func (l *MemoryLocker) TTL(name string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, ok := l.locks[name]
+	if !ok {
+		return 0, nil
+	}
+	if ttl := time.Until(current.expires); ttl > 0 {
+		return ttl, nil
+	}
+	return 0, nil
+}
+
+// List returns the locks currently held whose names start with prefix, sorted
+// by name.
+func (l *MemoryLocker) List(prefix string) ([]Info, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var infos []Info
+	for name, current := range l.locks {
+		ttl := time.Until(current.expires)
+		if ttl <= 0 || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		infos = append(infos, Info{Name: name, Owner: current.owner, TTL: ttl})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// ForceUnlock unlocks a lock regardless of the unique id holding it. Returns
+// ErrUnlock if the lock is not held.
+func (l *MemoryLocker) ForceUnlock(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, ok := l.locks[name]
+	if !ok || !time.Now().Before(current.expires) {
+		return ErrUnlock
+	}
+	delete(l.locks, name)
+	return nil
+}
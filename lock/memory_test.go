@@ -0,0 +1,31 @@
+package lock
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLockerSweep(t *testing.T) {
+	l := NewMemoryLocker()
+
+	for i := 0; i < 10*sweepEvery; i++ {
+		ok, err := l.Lock(fmt.Sprintf("foo:%d", i), "host1", time.Nanosecond)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.True(t, len(l.locks) <= sweepEvery, "%d locks", len(l.locks))
+
+	// tokens keep increasing for locks that were swept.
+	_, first, err := l.LockWithToken("foo:0", "host1", time.Nanosecond)
+	require.NoError(t, err)
+	for i := 0; i < sweepEvery; i++ {
+		_, _, err := l.LockWithToken(fmt.Sprintf("bar:%d", i), "host1", time.Nanosecond)
+		require.NoError(t, err)
+	}
+	_, second, err := l.LockWithToken("foo:0", "host1", time.Nanosecond)
+	require.NoError(t, err)
+	require.Greater(t, second, first)
+}
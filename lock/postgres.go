@@ -0,0 +1,219 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresLocker is a PostgreSQL backed Locker implementation. By default locks
+// are rows in a lock table holding the owner and expiry of each lock. With the
+// AdvisoryLocks option, locks are instead session level advisory locks.
+type PostgresLocker struct {
+	db    postgres.CRUD
+	table string
+
+	advisory *sqlx.DB
+	mu       sync.Mutex
+	sessions map[string]advisorySession
+}
+
+type advisorySession struct {
+	owner string
+	conn  *sql.Conn
+}
+
+var _ (FencingLocker) = (*PostgresLocker)(nil)
+
+// PostgresLockerOptFn is a functional option for configuring a PostgresLocker.
+type PostgresLockerOptFn func(*PostgresLocker)
+
+// AdvisoryLocks sets the PostgresLocker to acquire locks with
+// pg_try_advisory_lock on a dedicated connection from db instead of using the
+// lock table. An advisory lock is held until it is unlocked or its session
+// ends, so the duration given to Lock is ignored. Advisory locks do not issue
+// fencing tokens.
+func AdvisoryLocks(db *sqlx.DB) PostgresLockerOptFn {
+	return func(l *PostgresLocker) {
+		l.advisory = db
+	}
+}
+
+// NewPostgresLocker initializes a new PostgresLocker that stores locks in the
+// given table, which may be qualified by its schema such as `locks.locks`. The
+// table can be created with CreateTable. NewPostgresLocker panics if the table
+// is not a valid identifier, as checked by postgres.QuoteIdentifier, unless
+// using advisory locks.
+func NewPostgresLocker(db postgres.CRUD, table string, opts ...PostgresLockerOptFn) *PostgresLocker {
+	l := &PostgresLocker{
+		db:       db,
+		sessions: make(map[string]advisorySession),
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	if l.advisory == nil {
+		quoted, err := postgres.QuoteIdentifier(table)
+		if err != nil {
+			panic("lock: invalid PostgresLocker table: " + err.Error())
+		}
+		l.table = quoted
+	}
+	return l
+}
+
+// CreateTable creates the lock table if it does not already exist.
+func (l *PostgresLocker) CreateTable(ctx context.Context) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		name       TEXT PRIMARY KEY,
+		owner      TEXT NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		token      BIGINT NOT NULL DEFAULT 1
+	)`, l.table)
+
+	_, err := l.db.ExecContext(ctx, query)
+	return postgres.Err("lock", err)
+}
+
+// Lock attempts to acquire the lock. Returns true if the lock was acquired.
+func (l *PostgresLocker) Lock(name, uniqueID string, duration time.Duration) (bool, error) {
+	return l.LockContext(context.Background(), name, uniqueID, duration)
+}
+
+// LockContext is Lock with a context for the queries.
+func (l *PostgresLocker) LockContext(ctx context.Context, name, uniqueID string, duration time.Duration) (bool, error) {
+	if l.advisory != nil {
+		return l.advisoryLock(ctx, name, uniqueID)
+	}
+	acquired, _, err := l.LockWithTokenContext(ctx, name, uniqueID, duration)
+	return acquired, err
+}
+
+// LockWithToken attempts to acquire the lock. Returns true and the fencing token
+// if the lock was acquired. The token is incremented each time the lock is
+// newly acquired. Returns ErrFencingUnsupported when using advisory locks.
+func (l *PostgresLocker) LockWithToken(name, uniqueID string, duration time.Duration) (bool, int64, error) {
+	return l.LockWithTokenContext(context.Background(), name, uniqueID, duration)
+}
+
+// LockWithTokenContext is LockWithToken with a context for the query.
+func (l *PostgresLocker) LockWithTokenContext(ctx context.Context, name, uniqueID string, duration time.Duration) (bool, int64, error) {
+	if l.advisory != nil {
+		return false, 0, ErrFencingUnsupported
+	}
+
+	query := l.db.Rebind(fmt.Sprintf(`
+	INSERT INTO %s AS l (name, owner, expires_at)
+	VALUES (?, ?, now() + ?::float8 * interval '1 millisecond')
+	ON CONFLICT (name) DO UPDATE SET
+		owner = EXCLUDED.owner,
+		expires_at = EXCLUDED.expires_at,
+		token = CASE
+			WHEN l.owner = EXCLUDED.owner AND l.expires_at > now() THEN l.token
+			ELSE l.token + 1
+		END
+	WHERE l.owner = EXCLUDED.owner OR l.expires_at <= now()
+	RETURNING token`, l.table))
+
+	var token int64
+	err := l.db.GetContext(ctx, &token, query, name, uniqueID, int64(duration/time.Millisecond))
+	if err == sql.ErrNoRows {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, postgres.Err("lock", err)
+	}
+	return true, token, nil
+}
+
+// Unlock attempts to unlock a lock, but only if the uniqueID is the one that has the lock.
+func (l *PostgresLocker) Unlock(name, uniqueID string) error {
+	return l.UnlockContext(context.Background(), name, uniqueID)
+}
+
+// UnlockContext is Unlock with a context for the query.
+func (l *PostgresLocker) UnlockContext(ctx context.Context, name, uniqueID string) error {
+	if l.advisory != nil {
+		return l.advisoryUnlock(ctx, name, uniqueID)
+	}
+
+	// the row is expired rather than deleted so the fencing token keeps
+	// increasing across holders.
+	query := l.db.Rebind(fmt.Sprintf(`
+	UPDATE %s SET owner = '', expires_at = now()
+	WHERE name = ? AND owner = ? AND expires_at > now()`, l.table))
+
+	res, err := l.db.ExecContext(ctx, query, name, uniqueID)
+	if err != nil {
+		return postgres.Err("lock", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return postgres.Err("lock", err)
+	} else if n != 1 {
+		return ErrUnlock
+	}
+	return nil
+}
+
+func (l *PostgresLocker) advisoryLock(ctx context.Context, name, uniqueID string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// advisory locks are reentrant within a session, so ownership is tracked
+	// here to keep other unique ids in this process from acquiring them.
+	if s, ok := l.sessions[name]; ok {
+		if s.owner != uniqueID {
+			return false, nil
+		}
+		if err := s.conn.PingContext(ctx); err != nil {
+			// the session has ended and the lock with it.
+			delete(l.sessions, name)
+			s.conn.Close()
+			return false, postgres.Err("lock", err)
+		}
+		return true, nil
+	}
+
+	conn, err := l.advisory.DB.Conn(ctx)
+	if err != nil {
+		return false, postgres.Err("lock", err)
+	}
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Close()
+		return false, postgres.Err("lock", err)
+	}
+
+	l.sessions[name] = advisorySession{owner: uniqueID, conn: conn}
+	return true, nil
+}
+
+func (l *PostgresLocker) advisoryUnlock(ctx context.Context, name, uniqueID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.sessions[name]
+	if !ok || s.owner != uniqueID {
+		return ErrUnlock
+	}
+	delete(l.sessions, name)
+	defer s.conn.Close()
+
+	var released bool
+	err := s.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", name).Scan(&released)
+	if err != nil {
+		return postgres.Err("lock", err)
+	}
+	if !released {
+		return ErrUnlock
+	}
+	return nil
+}
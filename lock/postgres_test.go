@@ -0,0 +1,198 @@
+// +build int
+
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/testhelpers/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresLocker(t *testing.T) {
+	expiration := 200 * time.Millisecond
+	const name = "foo"
+
+	setup := func(t *testing.T, opts ...PostgresLockerOptFn) (*sqlx.DB, *PostgresLocker) {
+		db := postgres.Setup(t)
+		_, err := db.Exec("DROP TABLE IF EXISTS test_locks")
+		require.NoError(t, err)
+
+		l := NewPostgresLocker(db, "test_locks", opts...)
+		require.NoError(t, l.CreateTable(context.Background()))
+		return db, l
+	}
+
+	t.Run("normal lock expiration", func(t *testing.T) {
+		db, l := setup(t)
+		defer db.Close()
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		time.Sleep(100*time.Millisecond + expiration)
+
+		// should be able to get the lock now
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("refresh lock", func(t *testing.T) {
+		db, l := setup(t)
+		defer db.Close()
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		// set the lock again, this time 3x the expiration
+		result, err = l.Lock(name, "host1", 3*expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		time.Sleep(100*time.Millisecond + expiration)
+
+		// should not be able to get the lock
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		// should be able to get the lock now
+		time.Sleep(2 * expiration)
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("unlock", func(t *testing.T) {
+		db, l := setup(t)
+		defer db.Close()
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		// try a host2 unlock - should fail
+		require.Equal(t, ErrUnlock, l.Unlock(name, "host2"))
+
+		// host1 should be able to unlock it
+		require.NoError(t, l.Unlock(name, "host1"))
+		require.Equal(t, ErrUnlock, l.Unlock(name, "host1"))
+
+		// host2 should be able to immedately acquire the lock
+		result, err = l.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("fencing tokens", func(t *testing.T) {
+		db, l := setup(t)
+		defer db.Close()
+
+		result, token, err := l.LockWithToken(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Equal(t, int64(1), token)
+
+		// refreshing keeps the same token
+		result, token, err = l.LockWithToken(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Equal(t, int64(1), token)
+
+		require.NoError(t, l.Unlock(name, "host1"))
+
+		// a new hold on the lock issues a newer token
+		result, token, err = l.LockWithToken(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Equal(t, int64(2), token)
+	})
+
+	t.Run("schema qualified table", func(t *testing.T) {
+		db := postgres.Setup(t)
+		defer db.Close()
+		_, err := db.Exec("DROP SCHEMA IF EXISTS test_lock_schema CASCADE; CREATE SCHEMA test_lock_schema")
+		require.NoError(t, err)
+
+		l := NewPostgresLocker(db, "test_lock_schema.locks")
+		require.NoError(t, l.CreateTable(context.Background()))
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.NoError(t, l.Unlock(name, "host1"))
+	})
+
+	t.Run("invalid table", func(t *testing.T) {
+		db := postgres.Setup(t)
+		defer db.Close()
+
+		require.Panics(t, func() { NewPostgresLocker(db, "locks; DROP TABLE users") })
+	})
+
+	t.Run("context", func(t *testing.T) {
+		db, l := setup(t)
+		defer db.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := l.LockContext(ctx, name, "host1", expiration)
+		require.Error(t, err)
+
+		result, err := l.LockContext(context.Background(), name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Error(t, l.UnlockContext(ctx, name, "host1"))
+		require.NoError(t, l.UnlockContext(context.Background(), name, "host1"))
+	})
+
+	t.Run("advisory locks", func(t *testing.T) {
+		db := postgres.Setup(t)
+		defer db.Close()
+		l1 := NewPostgresLocker(db, "", AdvisoryLocks(db))
+		l2 := NewPostgresLocker(db, "", AdvisoryLocks(db))
+
+		result, err := l1.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		// held on another session
+		result, err = l2.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		// held by another unique id on the same session
+		result, err = l1.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		// refresh, the duration is ignored
+		time.Sleep(100*time.Millisecond + expiration)
+		result, err = l1.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		_, _, err = l1.LockWithToken(name, "host1", expiration)
+		require.Equal(t, ErrFencingUnsupported, err)
+
+		require.Equal(t, ErrUnlock, l1.Unlock(name, "host2"))
+		require.NoError(t, l1.Unlock(name, "host1"))
+
+		result, err = l2.Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.NoError(t, l2.Unlock(name, "host2"))
+	})
+}
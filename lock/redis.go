@@ -3,21 +3,34 @@ package lock
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/garyburd/redigo/redis"
 )
 
-// RedisLocker is a Redis backed Locker implementation.
+// RedisLocker is a Redis backed Locker implementation. Each lock is the key of
+// its name appended to the prefix, and the fencing tokens of the locks acquired
+// with LockWithToken are the fields of a hash whose key is the prefix itself,
+// which no lock can have, as lock names must not be empty.
 type RedisLocker struct {
 	pool   *redis.Pool
 	prefix string
 }
 
-var _ (Locker) = (*RedisLocker)(nil)
+var (
+	_ (FencingLocker) = (*RedisLocker)(nil)
+	_ (ExtendLocker)  = (*RedisLocker)(nil)
+	_ (InspectLocker) = (*RedisLocker)(nil)
+)
 
-// NewRedisLocker initializes a new RedisLocker.
+// NewRedisLocker initializes a new RedisLocker. The prefix must not be empty,
+// so that the locks and fencing tokens are kept apart from any other keys;
+// NewRedisLocker panics if it is.
 func NewRedisLocker(pool *redis.Pool, prefix string) *RedisLocker {
+	if prefix == "" {
+		panic("lock: RedisLocker prefix must not be empty")
+	}
 	return &RedisLocker{
 		pool:   pool,
 		prefix: prefix,
@@ -25,31 +38,111 @@ func NewRedisLocker(pool *redis.Pool, prefix string) *RedisLocker {
 }
 
 // Lock attempts to acquire the lock. Returns true if the lock was acquired.
+// Locks acquired with Lock have no fencing token.
 func (l *RedisLocker) Lock(name, uniqueID string, duration time.Duration) (bool, error) {
 	const lockScript = `
-  	if redis.call("get",KEYS[1]) == ARGV[1] then
+	if redis.call("get",KEYS[1]) == ARGV[1] then
 		return redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
-    else
+	else
 		return redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2])
 	end`
 
+	if name == "" {
+		return false, errEmptyName
+	}
+
 	conn := l.pool.Get()
 	defer conn.Close()
 
 	cmd := redis.NewScript(1, lockScript)
-	res, err := cmd.Do(conn, l.key(name), uniqueID, fmt.Sprintf("%d", duration/time.Millisecond))
+	_, err := redis.String(cmd.Do(conn, l.key(name), uniqueID, fmt.Sprintf("%d", duration/time.Millisecond)))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LockWithToken attempts to acquire the lock. Returns true and the fencing token
+// if the lock was acquired. The token is incremented in the hash of fencing
+// tokens each time the lock is newly acquired.
+//
+// The token of each name locked with LockWithToken is kept in the hash for
+// good, so that tokens keep increasing across holders, so fencing tokens are
+// meant for a bounded set of lock names. A name whose tokens are used should
+// only be acquired with LockWithToken, as Lock does not issue a token.
+func (l *RedisLocker) LockWithToken(name, uniqueID string, duration time.Duration) (bool, int64, error) {
+	const lockScript = `
+	if redis.call("get",KEYS[1]) == ARGV[1] then
+		redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+		return tonumber(redis.call('HGET', KEYS[2], ARGV[3]) or '0')
+	elseif redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+		return redis.call('HINCRBY', KEYS[2], ARGV[3], 1)
+	else
+		return false
+	end`
+
+	if name == "" {
+		return false, 0, errEmptyName
+	}
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	cmd := redis.NewScript(2, lockScript)
+	token, err := redis.Int64(cmd.Do(conn, l.key(name), l.fenceKey(), uniqueID, fmt.Sprintf("%d", duration/time.Millisecond), name))
+	if err == redis.ErrNil {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	return true, token, nil
+}
+
+// Extend resets the expiry of the lock to duration if it is held by uniqueID.
+// Returns false if it is not, including when the lock has expired.
+func (l *RedisLocker) Extend(name, uniqueID string, duration time.Duration) (bool, error) {
+	const extendScript = `
+	if redis.call("get",KEYS[1]) == ARGV[1] then
+		return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	else
+		return 0
+	end`
+
+	if name == "" {
+		return false, nil
+	}
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	cmd := redis.NewScript(1, extendScript)
+	res, err := redis.Int(cmd.Do(conn, l.key(name), uniqueID, fmt.Sprintf("%d", duration/time.Millisecond)))
 	if err != nil {
 		return false, err
 	}
-	return res == "OK", nil
+	return res == 1, nil
 }
 
 // ErrUnlock is the error thrown when you try to unlock a lock that's expired or
 // a lock that was locked by another unique id.
 var ErrUnlock = errors.New("unlock failed, name or unique id incorrect")
 
+var errEmptyName = errors.New("lock name must not be empty")
+
 // Unlock attempts to unlock a lock, but only if the uniqueID is the one that has the lock.
 func (l *RedisLocker) Unlock(name, uniqueID string) error {
+	if name == "" {
+		return ErrUnlock
+	}
+	return unlockKey(l.pool, l.key(name), uniqueID)
+}
+
+// unlockKey deletes the key of a lock, but only if its value is uniqueID.
+func unlockKey(pool *redis.Pool, key, uniqueID string) error {
 	const unlockScript = `
 	if redis.call("get",KEYS[1]) == ARGV[1] then
 		return redis.call("del",KEYS[1])
@@ -57,11 +150,11 @@ func (l *RedisLocker) Unlock(name, uniqueID string) error {
 		return 0
 	end`
 
-	conn := l.pool.Get()
+	conn := pool.Get()
 	defer conn.Close()
 
 	cmd := redis.NewScript(1, unlockScript)
-	if res, err := redis.Int(cmd.Do(conn, l.key(name), uniqueID)); err != nil {
+	if res, err := redis.Int(cmd.Do(conn, key, uniqueID)); err != nil {
 		return err
 	} else if res != 1 {
 		return ErrUnlock
@@ -70,6 +163,144 @@ func (l *RedisLocker) Unlock(name, uniqueID string) error {
 	return nil
 }
 
+// Owner returns the unique id holding the lock, or an empty string if the lock
+// is not held.
+func (l *RedisLocker) Owner(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	owner, err := redis.String(conn.Do("GET", l.key(name)))
+	if err == redis.ErrNil {
+		return "", nil
+	}
+	return owner, err
+}
+
+// TTL returns the time remaining before the lock expires, 0 if the lock is not
+// held, or a negative duration if the lock never expires.
+func (l *RedisLocker) TTL(name string) (time.Duration, error) {
+	if name == "" {
+		return 0, nil
+	}
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	ms, err := redis.Int64(conn.Do("PTTL", l.key(name)))
+	if err != nil {
+		return 0, err
+	}
+	return pttl(ms), nil
+}
+
+// List returns the locks currently held whose names start with prefix. The
+// keys are found with SCAN, so locks acquired or released while listing may or
+// may not be included.
+func (l *RedisLocker) List(prefix string) ([]Info, error) {
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	match := escapeGlob(l.key(prefix)) + "*"
+	var keys []string
+	cursor := "0"
+	for {
+		res, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", match, "COUNT", 100))
+		if err != nil {
+			return nil, err
+		}
+		var page []string
+		if _, err := redis.Scan(res, &cursor, &page); err != nil {
+			return nil, err
+		}
+		keys = append(keys, page...)
+		if cursor == "0" {
+			break
+		}
+	}
+
+	var infos []Info
+	for _, key := range keys {
+		if key == l.fenceKey() {
+			continue
+		}
+		conn.Send("GET", key)
+		conn.Send("PTTL", key)
+		if err := conn.Flush(); err != nil {
+			return nil, err
+		}
+		owner, ownerErr := redis.String(conn.Receive())
+		ms, err := redis.Int64(conn.Receive())
+		if err != nil {
+			return nil, err
+		}
+		// skip locks released since the scan and keys that are not locks.
+		if ownerErr != nil {
+			if _, ok := ownerErr.(redis.Error); ok || ownerErr == redis.ErrNil {
+				continue
+			}
+			return nil, ownerErr
+		}
+
+		infos = append(infos, Info{
+			Name:  strings.TrimPrefix(key, l.prefix),
+			Owner: owner,
+			TTL:   pttl(ms),
+		})
+	}
+	return infos, nil
+}
+
+// ForceUnlock unlocks a lock regardless of the unique id holding it. Returns
+// ErrUnlock if the lock is not held.
+func (l *RedisLocker) ForceUnlock(name string) error {
+	if name == "" {
+		return ErrUnlock
+	}
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	if res, err := redis.Int(conn.Do("DEL", l.key(name))); err != nil {
+		return err
+	} else if res != 1 {
+		return ErrUnlock
+	}
+	return nil
+}
+
+// pttl converts the result of PTTL to a duration, where -2 is returned for a
+// missing key and -1 for a key without an expiry.
+func pttl(ms int64) time.Duration {
+	switch ms {
+	case -2:
+		return 0
+	case -1:
+		return -1
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// escapeGlob escapes the characters that SCAN's MATCH treats as patterns.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 func (l *RedisLocker) key(name string) string {
 	return l.prefix + name
 }
+
+func (l *RedisLocker) fenceKey() string {
+	return l.prefix
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/graymeta/gmkit/testhelpers/redis"
 
+	redigo "github.com/garyburd/redigo/redis"
 	"github.com/stretchr/testify/require"
 )
 
@@ -92,4 +93,119 @@ func TestRedisLocker(t *testing.T) {
 		require.NoError(t, err)
 		require.True(t, result)
 	})
+
+	t.Run("fencing tokens", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRedisLocker(pool, "somePrefix:")
+
+		result, token, err := l.LockWithToken(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Equal(t, int64(1), token)
+
+		// refreshing keeps the same token
+		result, token, err = l.LockWithToken(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Equal(t, int64(1), token)
+
+		result, token, err = l.LockWithToken(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+		require.Zero(t, token)
+
+		require.NoError(t, l.Unlock(name, "host1"))
+
+		// a new hold on the lock issues a newer token
+		result, token, err = l.LockWithToken(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.Equal(t, int64(2), token)
+	})
+
+	t.Run("fencing tokens are separate from locks", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRedisLocker(pool, "somePrefix:")
+
+		// a lock whose name looks like a fencing token key is an ordinary lock.
+		for _, n := range []string{name, name + ":fence", "fence:" + name} {
+			result, token, err := l.LockWithToken(n, "host1", expiration)
+			require.NoError(t, err)
+			require.True(t, result)
+			require.Equal(t, int64(1), token)
+		}
+
+		infos, err := l.List("")
+		require.NoError(t, err)
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name)
+		}
+		require.ElementsMatch(t, []string{name, name + ":fence", "fence:" + name}, names)
+
+		_, err = l.Lock("", "host1", expiration)
+		require.Error(t, err)
+		require.Equal(t, ErrUnlock, l.ForceUnlock(""))
+	})
+
+	t.Run("only LockWithToken issues fencing tokens", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRedisLocker(pool, "somePrefix:")
+
+		result, err := l.Lock("plain", "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		result, _, err = l.LockWithToken(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		conn := pool.Get()
+		defer conn.Close()
+		fields, err := redigo.Strings(conn.Do("HKEYS", "somePrefix:"))
+		require.NoError(t, err)
+		require.Equal(t, []string{name}, fields)
+	})
+
+	t.Run("extend", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRedisLocker(pool, "somePrefix:")
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		result, err = l.Extend(name, "host2", 3*expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		result, err = l.Extend(name, "host1", 3*expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		ttl, err := l.TTL(name)
+		require.NoError(t, err)
+		require.True(t, ttl > expiration, "ttl %s", ttl)
+
+		// an expired lock is not acquired again.
+		require.NoError(t, l.Unlock(name, "host1"))
+		result, err = l.Extend(name, "host1", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		owner, err := l.Owner(name)
+		require.NoError(t, err)
+		require.Empty(t, owner)
+	})
+
+	t.Run("empty prefix", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+
+		require.Panics(t, func() { NewRedisLocker(pool, "") })
+	})
 }
@@ -0,0 +1,171 @@
+package lock
+
+import (
+	"errors"
+	"time"
+
+	gmerrors "github.com/graymeta/gmkit/errors"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// clockDriftFactor is the fraction of the lock duration allowed for clock drift
+// between the Redis instances, as recommended by the Redlock algorithm.
+const clockDriftFactor = 0.01
+
+// defaultUnlockTimeout is how long each instance is given to respond to Unlock
+// without the InstanceTimeout option.
+const defaultUnlockTimeout = time.Second
+
+// errInstanceTimeout is the error of an instance that did not respond within
+// the instance timeout.
+var errInstanceTimeout = errors.New("redlock instance timed out")
+
+// Redlock is a Locker implementation that acquires a lock on a majority of
+// independent Redis instances using the Redlock algorithm, so that the lock
+// survives the loss of a minority of the instances.
+type Redlock struct {
+	lockers []*RedisLocker
+	quorum  int
+	timeout time.Duration
+}
+
+var _ (Locker) = (*Redlock)(nil)
+
+// RedlockOptFn is a functional option for configuring a Redlock.
+type RedlockOptFn func(*Redlock)
+
+// InstanceTimeout sets how long each instance is given to respond, after which
+// it counts as having failed, so that an unresponsive instance does not block
+// Lock or Unlock. Defaults to a tenth of the lock duration for Lock, and to a
+// second for Unlock.
+func InstanceTimeout(d time.Duration) RedlockOptFn {
+	return func(l *Redlock) {
+		l.timeout = d
+	}
+}
+
+// NewRedlock initializes a new Redlock across the given pools, each of which
+// should connect to an independent Redis instance.
+func NewRedlock(pools []*redis.Pool, prefix string, opts ...RedlockOptFn) *Redlock {
+	lockers := make([]*RedisLocker, 0, len(pools))
+	for _, p := range pools {
+		lockers = append(lockers, NewRedisLocker(p, prefix))
+	}
+	l := &Redlock{
+		lockers: lockers,
+		quorum:  len(pools)/2 + 1,
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// Lock attempts to acquire the lock on all of the instances. Returns true if the
+// lock was acquired on a majority of them before the lock's validity, the
+// duration less the time taken and an allowance for clock drift, elapsed. If it
+// was not, the lock is released on all of the instances. Calling Lock while
+// holding the lock refreshes it.
+func (l *Redlock) Lock(name, uniqueID string, duration time.Duration) (bool, error) {
+	timeout := l.timeout
+	if timeout <= 0 {
+		timeout = duration / 10
+	}
+
+	start := time.Now()
+	acquired, errs := l.each(timeout, func(rl *RedisLocker) (bool, error) {
+		return rl.Lock(name, uniqueID, duration)
+	})
+
+	drift := time.Duration(float64(duration)*clockDriftFactor) + 2*time.Millisecond
+	validity := duration - time.Since(start) - drift
+	if acquired >= l.quorum && validity > 0 {
+		return true, nil
+	}
+
+	l.each(timeout, func(rl *RedisLocker) (bool, error) {
+		return true, rl.Unlock(name, uniqueID)
+	})
+
+	// only report errors when they prevented reaching a quorum.
+	var err error
+	if len(l.lockers)-len(errs) < l.quorum {
+		for _, e := range errs {
+			err = gmerrors.Append(err, e)
+		}
+	}
+	return false, err
+}
+
+// Unlock attempts to unlock the lock on all of the instances, but only if the
+// uniqueID is the one that has the lock. Returns ErrUnlock if the lock was not
+// held on a majority of the instances.
+func (l *Redlock) Unlock(name, uniqueID string) error {
+	timeout := l.timeout
+	if timeout <= 0 {
+		timeout = defaultUnlockTimeout
+	}
+
+	unlocked, errs := l.each(timeout, func(rl *RedisLocker) (bool, error) {
+		return true, rl.Unlock(name, uniqueID)
+	})
+	if unlocked >= l.quorum {
+		return nil
+	}
+
+	var err error
+	for _, e := range errs {
+		if e != ErrUnlock {
+			err = gmerrors.Append(err, e)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return ErrUnlock
+}
+
+// each calls fn on all of the lockers concurrently and returns how many calls
+// returned true without an error, along with any errors returned. Calls that
+// have not returned once the timeout elapses are left to finish in the
+// background and count as errInstanceTimeout.
+func (l *Redlock) each(timeout time.Duration, fn func(*RedisLocker) (bool, error)) (int, []error) {
+	type result struct {
+		ok  bool
+		err error
+	}
+
+	// buffered so that the calls that time out do not block once they return.
+	results := make(chan result, len(l.lockers))
+	for _, rl := range l.lockers {
+		go func(rl *RedisLocker) {
+			res, err := fn(rl)
+			results <- result{ok: res, err: err}
+		}(rl)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var (
+		ok   int
+		errs []error
+	)
+	for pending := len(l.lockers); pending > 0; pending-- {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				errs = append(errs, r.err)
+			} else if r.ok {
+				ok++
+			}
+		case <-timer.C:
+			for ; pending > 0; pending-- {
+				errs = append(errs, errInstanceTimeout)
+			}
+			return ok, errs
+		}
+	}
+	return ok, errs
+}
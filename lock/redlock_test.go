@@ -0,0 +1,127 @@
+// +build int
+
+package lock
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/testhelpers/redis"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedlock(t *testing.T) {
+	const expiration = 200 * time.Millisecond
+	const name = "foo"
+
+	setup := func(t *testing.T) []*redigo.Pool {
+		var pools []*redigo.Pool
+		for _, db := range []int{13, 14, 15} {
+			pool := redis.SetupDB(t, db)
+			t.Cleanup(func() { pool.Close() })
+			pools = append(pools, pool)
+		}
+		return pools
+	}
+
+	t.Run("acquires with a majority", func(t *testing.T) {
+		pools := setup(t)
+		l := NewRedlock(pools, "somePrefix:")
+
+		result, err := NewRedisLocker(pools[0], "somePrefix:").Lock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		result, err = l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		require.NoError(t, l.Unlock(name, "host1"))
+	})
+
+	t.Run("releases without a majority", func(t *testing.T) {
+		pools := setup(t)
+		l := NewRedlock(pools, "somePrefix:")
+
+		for _, p := range pools[:2] {
+			result, err := NewRedisLocker(p, "somePrefix:").Lock(name, "host2", expiration)
+			require.NoError(t, err)
+			require.True(t, result)
+		}
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		// the lock taken on the third instance has been released
+		result, err = NewRedisLocker(pools[2], "somePrefix:").Lock(name, "host3", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("not acquired within the validity", func(t *testing.T) {
+		l := NewRedlock(setup(t), "somePrefix:", InstanceTimeout(time.Second))
+
+		// the drift allowance exceeds the duration
+		result, err := l.Lock(name, "host1", time.Millisecond)
+		require.NoError(t, err)
+		require.False(t, result)
+	})
+
+	t.Run("errors preventing a majority", func(t *testing.T) {
+		pools := setup(t)
+		down := &redigo.Pool{Dial: func() (redigo.Conn, error) {
+			return redigo.Dial("tcp", "127.0.0.1:1")
+		}}
+		l := NewRedlock([]*redigo.Pool{pools[0], down, down}, "somePrefix:")
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.Error(t, err)
+		require.False(t, result)
+
+		l = NewRedlock([]*redigo.Pool{pools[0], pools[1], down}, "somePrefix:")
+		result, err = l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("unresponsive instances time out", func(t *testing.T) {
+		pools := setup(t)
+
+		// accepts connections but never replies.
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+			}
+		}()
+		hung := &redigo.Pool{Dial: func() (redigo.Conn, error) {
+			return redigo.Dial("tcp", ln.Addr().String())
+		}}
+
+		l := NewRedlock([]*redigo.Pool{pools[0], pools[1], hung}, "somePrefix:", InstanceTimeout(50*time.Millisecond))
+		start := time.Now()
+		result, err := l.Lock(name, "host1", time.Minute)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.NoError(t, l.Unlock(name, "host1"))
+		require.True(t, time.Since(start) < time.Second, "took %s", time.Since(start))
+
+		// without the option, the timeout is a tenth of the duration.
+		l = NewRedlock([]*redigo.Pool{pools[0], hung, hung}, "somePrefix:")
+		start = time.Now()
+		result, err = l.Lock(name, "host1", expiration)
+		require.Error(t, err)
+		require.False(t, result)
+		require.True(t, time.Since(start) < time.Second, "took %s", time.Since(start))
+	})
+}
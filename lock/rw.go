@@ -0,0 +1,141 @@
+package lock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// RWLocker is a Redis backed reader/writer lock. Any number of unique ids can
+// hold the lock in shared mode, or a single unique id can hold it in exclusive
+// mode. Shared holders are kept in a sorted set like a Semaphore, and the
+// exclusive holder in a key like a RedisLocker.
+//
+// RWLocker is a Locker for the exclusive mode, and Reader returns a Locker for
+// the shared mode, so either can be used with a UniqueLocker to acquire and
+// refresh a hold. An exclusive hold can only be acquired once there are no
+// shared holders. A unique id that fails to acquire the exclusive hold because
+// of shared holders becomes the pending writer until it acquires it, or until
+// the duration it asked for elapses without it trying again. While there is a
+// pending writer, no new shared holds are acquired, though the existing ones
+// can be refreshed, so that overlapping readers do not starve writers.
+type RWLocker struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+var _ (Locker) = (*RWLocker)(nil)
+
+// NewRWLocker initializes a new RWLocker.
+func NewRWLocker(pool *redis.Pool, prefix string) *RWLocker {
+	return &RWLocker{
+		pool:   pool,
+		prefix: prefix,
+	}
+}
+
+// Lock attempts to acquire the lock in exclusive mode. Returns true if the lock
+// was acquired.
+func (l *RWLocker) Lock(name, uniqueID string, duration time.Duration) (bool, error) {
+	const lockScript = `
+	local writer = redis.call('GET', KEYS[1])
+	if writer and writer ~= ARGV[1] then
+		return 0
+	end
+	local pending = redis.call('GET', KEYS[3])
+	redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', currentMillis())
+	if redis.call('ZCARD', KEYS[2]) > 0 then
+		if not pending or pending == ARGV[1] then
+			redis.call('SET', KEYS[3], ARGV[1], 'PX', ARGV[2])
+		end
+		return 0
+	end
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	if pending == ARGV[1] then
+		redis.call('DEL', KEYS[3])
+	end
+	return 1`
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	cmd := redis.NewScript(3, sortedSetHelpers+lockScript)
+	res, err := redis.Int(cmd.Do(conn, l.writerKey(name), l.readersKey(name), l.pendingKey(name), uniqueID, fmt.Sprintf("%d", duration/time.Millisecond)))
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// Unlock releases the exclusive hold of uniqueID on the lock.
+func (l *RWLocker) Unlock(name, uniqueID string) error {
+	return unlockKey(l.pool, l.writerKey(name), uniqueID)
+}
+
+// RLock attempts to acquire the lock in shared mode. Returns true if the lock
+// was acquired, or if uniqueID already holds it in shared mode, in which case it
+// is refreshed. A new shared hold is not acquired while a writer is pending.
+func (l *RWLocker) RLock(name, uniqueID string, duration time.Duration) (bool, error) {
+	const rlockScript = `
+	if redis.call('EXISTS', KEYS[1]) == 1 then
+		return 0
+	end
+	local now = currentMillis()
+	redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+	if redis.call('EXISTS', KEYS[3]) == 1 and not redis.call('ZSCORE', KEYS[2], ARGV[1]) then
+		return 0
+	end
+	addHolder(KEYS[2], ARGV[1], now, tonumber(ARGV[2]))
+	return 1`
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	cmd := redis.NewScript(3, sortedSetHelpers+rlockScript)
+	res, err := redis.Int(cmd.Do(conn, l.writerKey(name), l.readersKey(name), l.pendingKey(name), uniqueID, fmt.Sprintf("%d", duration/time.Millisecond)))
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// RUnlock releases the shared hold of uniqueID on the lock.
+func (l *RWLocker) RUnlock(name, uniqueID string) error {
+	return removeHolder(l.pool, l.readersKey(name), uniqueID)
+}
+
+// Readers returns the number of unique ids currently holding the lock in shared
+// mode.
+func (l *RWLocker) Readers(name string) (int, error) {
+	return countHolders(l.pool, l.readersKey(name))
+}
+
+// Reader returns a Locker that acquires and releases the lock in shared mode.
+func (l *RWLocker) Reader() Locker {
+	return rwReader{l}
+}
+
+func (l *RWLocker) writerKey(name string) string {
+	return l.prefix + name + ":writer"
+}
+
+func (l *RWLocker) readersKey(name string) string {
+	return l.prefix + name + ":readers"
+}
+
+func (l *RWLocker) pendingKey(name string) string {
+	return l.prefix + name + ":pending"
+}
+
+type rwReader struct {
+	l *RWLocker
+}
+
+func (r rwReader) Lock(name, uniqueID string, duration time.Duration) (bool, error) {
+	return r.l.RLock(name, uniqueID, duration)
+}
+
+func (r rwReader) Unlock(name, uniqueID string) error {
+	return r.l.RUnlock(name, uniqueID)
+}
@@ -0,0 +1,179 @@
+// +build int
+
+package lock
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/testhelpers/redis"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRWLocker(t *testing.T) {
+	const expiration = 200 * time.Millisecond
+	const name = "foo"
+
+	t.Run("shared holders block exclusive", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRWLocker(pool, "somePrefix:")
+
+		for _, host := range []string{"host1", "host2"} {
+			result, err := l.RLock(name, host, expiration)
+			require.NoError(t, err)
+			require.True(t, result)
+		}
+
+		readers, err := l.Readers(name)
+		require.NoError(t, err)
+		require.Equal(t, 2, readers)
+
+		result, err := l.Lock(name, "host3", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		require.NoError(t, l.RUnlock(name, "host1"))
+		require.Equal(t, ErrUnlock, l.RUnlock(name, "host1"))
+
+		// the remaining reader expires
+		time.Sleep(100*time.Millisecond + expiration)
+		result, err = l.Lock(name, "host3", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("pending writer blocks new shared holders", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRWLocker(pool, "somePrefix:")
+
+		result, err := l.RLock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		result, err = l.Lock(name, "host3", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		// new readers wait for the writer, existing ones can refresh.
+		result, err = l.RLock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+		result, err = l.RLock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		// another writer does not take over the pending writer.
+		result, err = l.Lock(name, "host4", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		require.NoError(t, l.RUnlock(name, "host1"))
+		result, err = l.Lock(name, "host3", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.NoError(t, l.Unlock(name, "host3"))
+
+		// readers are no longer blocked once the writer has acquired it.
+		result, err = l.RLock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("overlapping readers do not starve writers", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRWLocker(pool, "somePrefix:")
+
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(10 * time.Millisecond)
+			defer ticker.Stop()
+			// each reader holds the lock for 30ms and the next starts 10ms
+			// later, so there is always a reader holding it.
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+				}
+				reader := fmt.Sprintf("reader%d", i)
+				if result, err := l.RLock(name, reader, expiration); err != nil || !result {
+					continue
+				}
+				wg.Add(1)
+				time.AfterFunc(30*time.Millisecond, func() {
+					defer wg.Done()
+					l.RUnlock(name, reader)
+				})
+			}
+		}()
+		defer func() {
+			close(done)
+			wg.Wait()
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		readers, err := l.Readers(name)
+		require.NoError(t, err)
+		require.NotZero(t, readers)
+
+		deadline := time.Now().Add(time.Second)
+		for {
+			result, err := l.Lock(name, "writer", expiration)
+			require.NoError(t, err)
+			if result {
+				break
+			}
+			require.True(t, time.Now().Before(deadline), "writer starved")
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	t.Run("exclusive holder blocks shared", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRWLocker(pool, "somePrefix:")
+
+		result, err := l.Lock(name, "host1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		result, err = l.RLock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		require.NoError(t, l.Unlock(name, "host1"))
+		result, err = l.RLock(name, "host2", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+	})
+
+	t.Run("reader with a unique locker", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRWLocker(pool, "somePrefix:")
+		u1 := NewUniqueLocker(l.Reader(), "host1")
+		u2 := NewUniqueLocker(l.Reader(), "host2")
+
+		for _, u := range []*UniqueLocker{u1, u2} {
+			result, err := u.Lock(name, expiration)
+			require.NoError(t, err)
+			require.True(t, result)
+		}
+
+		require.NoError(t, u1.Unlock(name))
+		require.NoError(t, u2.Unlock(name))
+
+		readers, err := l.Readers(name)
+		require.NoError(t, err)
+		require.Zero(t, readers)
+	})
+}
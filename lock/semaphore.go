@@ -0,0 +1,114 @@
+package lock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/graymeta/gmkit/internal/redislua"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// Semaphore is a Redis backed counting semaphore that allows up to size unique
+// ids to hold a lock at once. Each holder is a member of a sorted set scored by
+// the time its hold expires. Semaphore is a Locker, so it can be used with a
+// UniqueLocker to acquire and refresh a hold.
+type Semaphore struct {
+	pool   *redis.Pool
+	prefix string
+	size   int
+}
+
+var _ (Locker) = (*Semaphore)(nil)
+
+// NewSemaphore initializes a new Semaphore allowing up to size holders of each
+// lock.
+func NewSemaphore(pool *redis.Pool, prefix string, size int) *Semaphore {
+	return &Semaphore{
+		pool:   pool,
+		prefix: prefix,
+		size:   size,
+	}
+}
+
+// Lock attempts to acquire one of the holds on the lock. Returns true if a hold
+// was acquired, or if uniqueID already has a hold, in which case it is
+// refreshed.
+func (s *Semaphore) Lock(name, uniqueID string, duration time.Duration) (bool, error) {
+	const lockScript = `
+	local now = currentMillis()
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+	if redis.call('ZSCORE', KEYS[1], ARGV[1]) or redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[3]) then
+		addHolder(KEYS[1], ARGV[1], now, tonumber(ARGV[2]))
+		return 1
+	end
+	return 0`
+
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	cmd := redis.NewScript(1, sortedSetHelpers+lockScript)
+	res, err := redis.Int(cmd.Do(conn, s.key(name), uniqueID, fmt.Sprintf("%d", duration/time.Millisecond), s.size))
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// Unlock releases the hold of uniqueID on the lock.
+func (s *Semaphore) Unlock(name, uniqueID string) error {
+	return removeHolder(s.pool, s.key(name), uniqueID)
+}
+
+// Holders returns the number of unique ids currently holding the lock.
+func (s *Semaphore) Holders(name string) (int, error) {
+	return countHolders(s.pool, s.key(name))
+}
+
+func (s *Semaphore) key(name string) string {
+	return s.prefix + name
+}
+
+// sortedSetHelpers are Lua functions shared by the scripts that keep holders in
+// a sorted set scored by the time their hold expires.
+const sortedSetHelpers = redislua.Time + `
+	local function currentMillis()
+		return math.floor(currentMicros() / 1000)
+	end
+
+	local function addHolder(key, holder, now, ttl)
+		redis.call('ZADD', key, now + ttl, holder)
+		if redis.call('PTTL', key) < ttl then
+			redis.call('PEXPIRE', key, ttl)
+		end
+	end
+`
+
+func removeHolder(pool *redis.Pool, key, uniqueID string) error {
+	const unlockScript = `
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', currentMillis())
+	return redis.call('ZREM', KEYS[1], ARGV[1])`
+
+	conn := pool.Get()
+	defer conn.Close()
+
+	cmd := redis.NewScript(1, sortedSetHelpers+unlockScript)
+	if res, err := redis.Int(cmd.Do(conn, key, uniqueID)); err != nil {
+		return err
+	} else if res != 1 {
+		return ErrUnlock
+	}
+	return nil
+}
+
+func countHolders(pool *redis.Pool, key string) (int, error) {
+	const countScript = `
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', currentMillis())
+	return redis.call('ZCARD', KEYS[1])`
+
+	conn := pool.Get()
+	defer conn.Close()
+
+	cmd := redis.NewScript(1, sortedSetHelpers+countScript)
+	return redis.Int(cmd.Do(conn, key))
+}
@@ -0,0 +1,55 @@
+// +build int
+
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/testhelpers/redis"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphore(t *testing.T) {
+	const expiration = 200 * time.Millisecond
+	const name = "foo"
+
+	t.Run("allows up to size holders", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		s := NewSemaphore(pool, "somePrefix:", 2)
+
+		for _, host := range []string{"host1", "host2"} {
+			result, err := s.Lock(name, host, expiration)
+			require.NoError(t, err)
+			require.True(t, result)
+		}
+
+		result, err := s.Lock(name, "host3", expiration)
+		require.NoError(t, err)
+		require.False(t, result)
+
+		holders, err := s.Holders(name)
+		require.NoError(t, err)
+		require.Equal(t, 2, holders)
+
+		// refreshing an existing hold does not need a free slot
+		result, err = s.Lock(name, "host1", 3*expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		require.NoError(t, s.Unlock(name, "host2"))
+		result, err = s.Lock(name, "host3", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+
+		// host3's hold expires, host1's was refreshed
+		time.Sleep(100*time.Millisecond + expiration)
+		holders, err = s.Holders(name)
+		require.NoError(t, err)
+		require.Equal(t, 1, holders)
+		require.Equal(t, ErrUnlock, s.Unlock(name, "host3"))
+		require.NoError(t, s.Unlock(name, "host1"))
+	})
+}
@@ -2,6 +2,7 @@ package lock
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/graymeta/gmkit/backoff"
@@ -29,6 +30,21 @@ func (l *UniqueLocker) Lock(name string, duration time.Duration) (bool, error) {
 	return l.locker.Lock(name, l.unique, duration)
 }
 
+// ErrFencingUnsupported is the error returned by LockWithToken when the
+// underlying Locker does not issue fencing tokens.
+var ErrFencingUnsupported = errors.New("locker does not support fencing tokens")
+
+// LockWithToken attempts to acquire the lock identified by name. Returns true
+// and the fencing token if the lock was acquired. Returns ErrFencingUnsupported
+// if the underlying Locker is not a FencingLocker.
+func (l *UniqueLocker) LockWithToken(name string, duration time.Duration) (bool, int64, error) {
+	fl, ok := l.locker.(FencingLocker)
+	if !ok {
+		return false, 0, ErrFencingUnsupported
+	}
+	return fl.LockWithToken(name, l.unique, duration)
+}
+
 // Unlock attempts to unlock the lock identified by name.
 func (l *UniqueLocker) Unlock(name string) error {
 	return l.locker.Unlock(name, l.unique)
@@ -38,18 +54,76 @@ func (l *UniqueLocker) Unlock(name string) error {
 // Refresh will periodically refresh the lock (attempts to refresh at refreshPeriod)
 // until the context is cancelled. This assumes the UniqueLocker has already
 // acquired the lock. This method should be run in a goroutine.
-func (l *UniqueLocker) Refresh(ctx context.Context, boff backoff.Backoffer, lockName string, lockDuration, refreshPeriod time.Duration) {
+//
+// Refresh returns ErrLockLost as soon as the lock is found to be held by another
+// uniqueID, or, if the Locker is an ExtendLocker, to have expired. Refresh
+// returns nil once the context is cancelled. When the backoff gives up on a
+// refresh, the lock is refreshed again at the next refresh period.
+func (l *UniqueLocker) Refresh(ctx context.Context, boff backoff.Backoffer, lockName string, lockDuration, refreshPeriod time.Duration) error {
+	for {
+		err := l.refresh(ctx, boff, lockName, lockDuration, refreshPeriod, 0)
+		if err == nil || err == ErrLockLost {
+			return err
+		}
+	}
+}
+
+// refresh refreshes the lock at each refreshPeriod until the context is
+// cancelled, returning nil, or until a refresh fails, returning ErrLockLost or
+// the error returned by the backoff. A non zero token is the fencing token the
+// lock was acquired with.
+func (l *UniqueLocker) refresh(ctx context.Context, boff backoff.Backoffer, lockName string, lockDuration, refreshPeriod time.Duration, token int64) error {
 	ticker := time.NewTicker(refreshPeriod)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
-			ticker.Stop()
-			return
+			return nil
 		case <-ticker.C:
-			boff.BackoffCtx(ctx, func(context.Context) error {
-				_, err := l.Lock(lockName, lockDuration)
-				return err
+			err := boff.BackoffCtx(ctx, func(context.Context) error {
+				acquired, err := l.refreshLock(lockName, lockDuration, token)
+				if err != nil {
+					return err
+				}
+				if !acquired {
+					return noRetry{ErrLockLost}
+				}
+				return nil
 			})
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, ErrLockLost) {
+				return ErrLockLost
+			}
+			if err != nil {
+				return err
+			}
 		}
 	}
 }
+
+// refreshLock refreshes a held lock. Returns false if it is no longer held with
+// the fencing token it was acquired with. An ExtendLocker extends the lock,
+// which fails once it has expired. Other Lockers acquire the lock again, so
+// for a FencingLocker the lock is lost if it was acquired with a new token,
+// as another unique id may have held it since it expired.
+func (l *UniqueLocker) refreshLock(name string, duration time.Duration, token int64) (bool, error) {
+	if el, ok := l.locker.(ExtendLocker); ok {
+		return el.Extend(name, l.unique, duration)
+	}
+	if token == 0 {
+		return l.Lock(name, duration)
+	}
+
+	acquired, current, err := l.LockWithToken(name, duration)
+	if err != nil || !acquired {
+		return false, err
+	}
+	if current != token {
+		// the lock was acquired anew for a lease that is lost, so release it.
+		l.Unlock(name)
+		return false, nil
+	}
+	return true, nil
+}
@@ -4,6 +4,7 @@ package lock
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -69,6 +70,30 @@ func TestUniqueLocker(t *testing.T) {
 		require.True(t, result)
 	})
 
+	t.Run("lease lost once the lock expires", func(t *testing.T) {
+		pool := redis.Setup(t)
+		defer pool.Close()
+		l := NewRedisLocker(pool, "somePrefix:")
+
+		lease, err := NewUniqueLocker(l, "l1").Acquire(context.Background(), name, expiration)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), lease.Token())
+
+		// the lock expires and is acquired again before the next refresh.
+		require.NoError(t, l.ForceUnlock(name))
+		result, err := l.Lock(name, "l1", expiration)
+		require.NoError(t, err)
+		require.True(t, result)
+		require.NoError(t, l.ForceUnlock(name))
+
+		select {
+		case <-lease.Lost():
+		case <-time.After(time.Second):
+			t.Fatal("lease should have been lost")
+		}
+		assert.Equal(t, ErrLockLost, lease.Err())
+	})
+
 	t.Run("Refresh", func(t *testing.T) {
 		l := &LockerMock{
 			LockFunc: func(lockName string, uniqueID string, duration time.Duration) (bool, error) {
@@ -88,4 +113,24 @@ func TestUniqueLocker(t *testing.T) {
 
 		assert.Len(t, l.LockCalls(), 2)
 	})
+
+	t.Run("Refresh retries after the backoff gives up", func(t *testing.T) {
+		var calls int
+		l := &LockerMock{
+			LockFunc: func(lockName string, uniqueID string, duration time.Duration) (bool, error) {
+				calls++
+				switch calls {
+				case 1:
+					return false, errors.New("connection refused")
+				case 2:
+					return true, nil
+				}
+				return false, nil
+			},
+		}
+
+		err := NewUniqueLocker(l, "l1").Refresh(context.Background(), backoff.NoopBackoff{}, name, time.Minute, 10*time.Millisecond)
+		require.Equal(t, ErrLockLost, err)
+		assert.Equal(t, 3, calls)
+	})
 }
@@ -0,0 +1,171 @@
+package pagetoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/graymeta/gmkit/crypter"
+	gmerrors "github.com/graymeta/gmkit/errors"
+)
+
+var (
+	// ErrInvalidToken is returned by a Codec when a token was not issued by the
+	// Codec, or has been modified. It maps to a 400 response.
+	ErrInvalidToken error = &gmerrors.HTTPErr{Msg: "invalid page token", Code: http.StatusBadRequest}
+
+	// ErrExpiredToken is returned by a Codec when a token has expired. It maps
+	// to a 400 response.
+	ErrExpiredToken error = &gmerrors.HTTPErr{Msg: "expired page token", Code: http.StatusBadRequest}
+)
+
+// CodecOptFn is a functional option for configuring a Codec.
+type CodecOptFn func(c *Codec)
+
+// Expiry sets how long tokens are valid for after they are issued. An expiry of
+// zero issues tokens that never expire. Defaults to 24 hours.
+func Expiry(d time.Duration) CodecOptFn {
+	return func(c *Codec) {
+		c.expiry = d
+	}
+}
+
+// Codec issues page tokens that clients cannot modify, for example to bypass
+// a MaxLimit, and that expire. Tokens are either signed with an HMAC, so that
+// clients can still decode them, or encrypted. Tokens obtained from a Codec
+// are encoded by it, so NextToken and PreviousToken issue tokens from the same
+// Codec.
+type Codec struct {
+	key     []byte
+	crypter crypter.Crypter
+	expiry  time.Duration
+	now     func() time.Time
+}
+
+// NewSignedCodec initializes a Codec that signs tokens with an HMAC-SHA256 of
+// the key.
+func NewSignedCodec(key []byte, opts ...CodecOptFn) *Codec {
+	return newCodec(&Codec{key: key}, opts...)
+}
+
+// NewEncryptedCodec initializes a Codec that encrypts tokens with c, which
+// must authenticate the ciphertext, as the Crypter from crypter.NewAESCrypter
+// does.
+func NewEncryptedCodec(c crypter.Crypter, opts ...CodecOptFn) *Codec {
+	return newCodec(&Codec{crypter: c}, opts...)
+}
+
+func newCodec(c *Codec, opts ...CodecOptFn) *Codec {
+	c.expiry = 24 * time.Hour
+	c.now = time.Now
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// GetTokenFromQuery provides the Token from the url.Values, as the package
+// level GetTokenFromQuery does. Unlike it, GetTokenFromQuery returns
+// ErrInvalidToken or ErrExpiredToken if the page token query param was not
+// issued by the Codec or has expired, or ErrInvalidToken if an option found it
+// invalid, rather than starting over from the first page.
+func (c *Codec) GetTokenFromQuery(q url.Values, opts ...TokenOptFn) (Token, error) {
+	t, err := c.Decode(q.Get(TokenQueryParam))
+	if err != nil {
+		return Token{}, err
+	}
+	t = fromQuery(t, q, opts...)
+	if t.invalid {
+		return Token{}, ErrInvalidToken
+	}
+	return t, nil
+}
+
+// Encode returns the token string of t, expiring after the Codec's expiry.
+// Returns "" if the token could not be encoded.
+func (c *Codec) Encode(t Token) string {
+	payload, err := t.marshal()
+	if err != nil {
+		return ""
+	}
+
+	var expires int64
+	if c.expiry > 0 {
+		expires = c.now().Add(c.expiry).Unix()
+	}
+	b := make([]byte, 8, 8+len(payload)+sha256.Size)
+	binary.BigEndian.PutUint64(b, uint64(expires))
+	b = append(b, payload...)
+
+	if c.crypter != nil {
+		ciphertext, err := c.crypter.Encrypt(string(b))
+		if err != nil {
+			return ""
+		}
+		// the crypter encodes the ciphertext in standard base64, which is not
+		// safe in a URL.
+		if b, err = base64.StdEncoding.DecodeString(ciphertext); err != nil {
+			return ""
+		}
+	} else {
+		b = append(b, c.sign(b)...)
+	}
+	return base64.URLEncoding.EncodeToString(b)
+}
+
+// Decode returns the Token of a token string issued by the Codec. Returns
+// ErrInvalidToken if the token was not issued by the Codec or was modified,
+// and ErrExpiredToken if it has expired. An empty string decodes to an empty
+// Token.
+func (c *Codec) Decode(token string) (Token, error) {
+	if token == "" {
+		return Token{codec: c}, nil
+	}
+
+	b, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return Token{}, ErrInvalidToken
+	}
+
+	if c.crypter != nil {
+		plaintext, err := c.crypter.Decrypt(base64.StdEncoding.EncodeToString(b))
+		if err != nil {
+			return Token{}, ErrInvalidToken
+		}
+		b = []byte(plaintext)
+	} else {
+		if len(b) < sha256.Size {
+			return Token{}, ErrInvalidToken
+		}
+		mac := b[len(b)-sha256.Size:]
+		b = b[:len(b)-sha256.Size]
+		if !hmac.Equal(mac, c.sign(b)) {
+			return Token{}, ErrInvalidToken
+		}
+	}
+
+	if len(b) < 8 {
+		return Token{}, ErrInvalidToken
+	}
+	expires := int64(binary.BigEndian.Uint64(b))
+	if expires != 0 && c.now().Unix() >= expires {
+		return Token{}, ErrExpiredToken
+	}
+
+	t, err := unmarshalToken(b[8:])
+	if err != nil {
+		return Token{}, ErrInvalidToken
+	}
+	t.codec = c
+	return t, nil
+}
+
+func (c *Codec) sign(b []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(b)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,139 @@
+package pagetoken
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/crypter"
+	gmerrors "github.com/graymeta/gmkit/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	aes, err := crypter.NewAESCrypter([]byte("012345678901234567890123456789ab"))
+	require.NoError(t, err)
+
+	codecs := []struct {
+		name     string
+		newCodec func(opts ...CodecOptFn) *Codec
+	}{
+		{
+			name: "signed",
+			newCodec: func(opts ...CodecOptFn) *Codec {
+				return NewSignedCodec([]byte("secret"), opts...)
+			},
+		},
+		{
+			name: "encrypted",
+			newCodec: func(opts ...CodecOptFn) *Codec {
+				return NewEncryptedCodec(aes, opts...)
+			},
+		},
+	}
+
+	for _, tc := range codecs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("round trip", func(t *testing.T) {
+				c := tc.newCodec()
+				tok := Token{Limit: 10, Offset: 20, Params: map[string]string{"sort": "name"}}
+
+				q := url.Values{TokenQueryParam: []string{c.Encode(tok)}}
+				got, err := c.GetTokenFromQuery(q, MaxLimit(5))
+				require.NoError(t, err)
+				assert.Equal(t, 5, got.Limit)
+				assert.Equal(t, 20, got.Offset)
+				assert.Equal(t, "name", got.GetParam("sort"))
+			})
+
+			t.Run("derived tokens are encoded by the codec", func(t *testing.T) {
+				c := tc.newCodec()
+				tok, err := c.GetTokenFromQuery(url.Values{LimitQueryParam: []string{"10"}})
+				require.NoError(t, err)
+
+				next, err := c.Decode(tok.NextToken(10, 100))
+				require.NoError(t, err)
+				assert.Equal(t, 10, next.Offset)
+
+				prev, err := c.Decode(next.PreviousToken())
+				require.NoError(t, err)
+				assert.Equal(t, 0, prev.Offset)
+			})
+
+			t.Run("rejects modified tokens", func(t *testing.T) {
+				c := tc.newCodec()
+				b, err := base64.URLEncoding.DecodeString(c.Encode(Token{Limit: 10}))
+				require.NoError(t, err)
+
+				for i := range b {
+					modified := append([]byte(nil), b...)
+					modified[i] ^= 1
+					_, err := c.Decode(base64.URLEncoding.EncodeToString(modified))
+					require.Equal(t, ErrInvalidToken, err, "byte %d", i)
+				}
+			})
+
+			t.Run("rejects tokens of other codecs", func(t *testing.T) {
+				c := tc.newCodec()
+				for _, token := range []string{
+					Token{Limit: 10}.String(),
+					NewSignedCodec([]byte("other")).Encode(Token{Limit: 10}),
+					"not a token",
+					"AAAA",
+				} {
+					_, err := c.Decode(token)
+					assert.Equal(t, ErrInvalidToken, err, token)
+				}
+			})
+
+			t.Run("rejects expired tokens", func(t *testing.T) {
+				now := time.Now()
+				c := tc.newCodec(Expiry(time.Minute))
+				c.now = func() time.Time { return now }
+				token := c.Encode(Token{Limit: 10})
+
+				now = now.Add(59 * time.Second)
+				_, err := c.Decode(token)
+				require.NoError(t, err)
+
+				now = now.Add(time.Second)
+				_, err = c.GetTokenFromQuery(url.Values{TokenQueryParam: []string{token}})
+				assert.Equal(t, ErrExpiredToken, err)
+			})
+
+			t.Run("tokens without expiry", func(t *testing.T) {
+				now := time.Now()
+				c := tc.newCodec(Expiry(0))
+				c.now = func() time.Time { return now }
+				token := c.Encode(Token{Limit: 10})
+
+				now = now.Add(365 * 24 * time.Hour)
+				_, err := c.Decode(token)
+				assert.NoError(t, err)
+			})
+		})
+	}
+
+	t.Run("package level GetTokenFromQuery starts over instead", func(t *testing.T) {
+		q := url.Values{TokenQueryParam: []string{"not a token"}, LimitQueryParam: []string{"10"}}
+
+		_, err := NewSignedCodec([]byte("secret")).GetTokenFromQuery(q)
+		assert.Equal(t, ErrInvalidToken, err)
+
+		tok := GetTokenFromQuery(q)
+		assert.Equal(t, 10, tok.Limit)
+		assert.Zero(t, tok.Offset)
+	})
+
+	t.Run("errors map to bad request", func(t *testing.T) {
+		for _, err := range []error{ErrInvalidToken, ErrExpiredToken} {
+			httpErr, ok := err.(gmerrors.HTTP)
+			require.True(t, ok)
+			assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode())
+		}
+	})
+}
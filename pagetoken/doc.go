@@ -1,4 +1,33 @@
 // Package pagetoken pkg provides a token pagination mechanism determined by the limit, offset,
 // and any other optional parameters that have been added to the pagetoken. See example tests
 // for an example of the behavior this package provides.
+//
+// # Token format
+//
+// A token is the URL safe base64 encoding, with padding, of a version byte
+// followed by the token in that version's format. Clients in other languages
+// may decode tokens to introspect them, but should treat them as opaque when
+// making requests. Tokens issued by a Codec that signs them hold an 8 byte big
+// endian unix expiry time, the token and a 32 byte HMAC, and tokens issued by
+// a Codec that encrypts them cannot be introspected.
+//
+// Version 1 tokens are the byte 0x01 followed by compact JSON with the fields
+// below, each of which is omitted when empty:
+//
+//	limit    number  the page size
+//	offset   number  the offset of the page, in offset pagination
+//	params   object  string values of the params of the endpoint
+//	sort     array   objects with a "field" string, and a "desc" bool
+//	filters  array   objects with a "field" string, an "op" string of eq, ne,
+//	                 lt, lte, gt, gte, in or like, and a "values" string array
+//	keyset   object  the position of the page in keyset pagination, with a
+//	                 "values" array of the values of the order fields in the
+//	                 last row of the previous page
+//
+// For example, before base64 encoding:
+//
+//	\x01{"limit":10,"sort":[{"field":"name","desc":true}],"filters":[{"field":"status","op":"in","values":["new","done"]}]}
+//
+// Tokens issued before the format was versioned are gob encoded, and are still
+// accepted during the migration to the versioned format.
 package pagetoken
@@ -0,0 +1,114 @@
+package pagetoken
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// formatVersion is the version byte that starts the current token format.
+// Legacy gob tokens start with the length of their first message, which is
+// never 1.
+const formatVersion = 1
+
+// The JSON encoding of a version 1 token. See the package documentation for
+// the format.
+type (
+	wireToken struct {
+		Limit   int               `json:"limit,omitempty"`
+		Offset  int               `json:"offset,omitempty"`
+		Params  map[string]string `json:"params,omitempty"`
+		Sort    []wireSort        `json:"sort,omitempty"`
+		Filters []wireFilter      `json:"filters,omitempty"`
+		Keyset  *wireKeyset       `json:"keyset,omitempty"`
+	}
+
+	wireSort struct {
+		Field string `json:"field"`
+		Desc  bool   `json:"desc,omitempty"`
+	}
+
+	wireFilter struct {
+		Field  string   `json:"field"`
+		Op     FilterOp `json:"op"`
+		Values []string `json:"values"`
+	}
+
+	wireKeyset struct {
+		Values []interface{} `json:"values"`
+	}
+)
+
+// marshal encodes the token in the current format: the version byte followed
+// by compact JSON.
+func (t Token) marshal() ([]byte, error) {
+	w := wireToken{
+		Limit:  t.Limit,
+		Offset: t.Offset,
+		Params: t.Params,
+	}
+	for _, s := range t.Sort {
+		w.Sort = append(w.Sort, wireSort{Field: s.Field, Desc: s.Desc})
+	}
+	for _, f := range t.Filters {
+		w.Filters = append(w.Filters, wireFilter{Field: f.Field, Op: f.Op, Values: f.Values})
+	}
+	if t.Keyset != nil {
+		// the order is set by the server, so is not held by the token.
+		w.Keyset = &wireKeyset{Values: t.Keyset.Values}
+	}
+
+	b, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{formatVersion}, b...), nil
+}
+
+// unmarshalToken decodes a token in the current format, or a legacy gob token.
+func unmarshalToken(b []byte) (Token, error) {
+	if len(b) == 0 {
+		return Token{}, errors.New("empty token")
+	}
+	if b[0] != formatVersion {
+		return unmarshalGob(b)
+	}
+
+	var w wireToken
+	dec := json.NewDecoder(bytes.NewReader(b[1:]))
+	// keep the precision of numeric keyset values, such as int64 ids.
+	dec.UseNumber()
+	if err := dec.Decode(&w); err != nil {
+		return Token{}, fmt.Errorf("decoding token: %v", err)
+	}
+
+	t := Token{
+		Limit:  w.Limit,
+		Offset: w.Offset,
+		Params: w.Params,
+	}
+	for _, s := range w.Sort {
+		t.Sort = append(t.Sort, SortField{Field: s.Field, Desc: s.Desc})
+	}
+	for _, f := range w.Filters {
+		t.Filters = append(t.Filters, Filter{Field: f.Field, Op: f.Op, Values: f.Values})
+	}
+	if w.Keyset != nil {
+		t.Keyset = &Keyset{Values: w.Keyset.Values}
+	}
+	return t, nil
+}
+
+// unmarshalGob decodes a legacy gob token.
+//
+// Deprecated: gob tokens are no longer issued, and are only decoded so that
+// tokens issued before the current format remain valid until they expire.
+func unmarshalGob(b []byte) (Token, error) {
+	var t Token
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&t); err != nil {
+		return Token{}, err
+	}
+	return t, nil
+}
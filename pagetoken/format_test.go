@@ -0,0 +1,77 @@
+package pagetoken
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		tok := Token{
+			Limit:   10,
+			Offset:  20,
+			Params:  map[string]string{"q": "cats"},
+			Sort:    []SortField{{Field: "name"}, {Field: "id", Desc: true}},
+			Filters: []Filter{{Field: "status", Op: OpIn, Values: []string{"new", "done"}}},
+			Keyset: &Keyset{
+				Values: []interface{}{"felix", json.Number("12345678901234567")},
+			},
+		}
+
+		assert.Equal(t, tok, GetToken(tok.String()))
+	})
+
+	t.Run("keyset order is not held by the token", func(t *testing.T) {
+		tok := Token{
+			Limit: 10,
+			Keyset: &Keyset{
+				Order:  []postgres.OrderPair{{Col: "name"}, {Col: "id", Desc: true}},
+				Values: []interface{}{"felix", "id9"},
+			},
+		}
+
+		b, err := base64.URLEncoding.DecodeString(tok.String())
+		require.NoError(t, err)
+		assert.Equal(t, `{"limit":10,"keyset":{"values":["felix","id9"]}}`, string(b[1:]))
+	})
+
+	t.Run("documented format", func(t *testing.T) {
+		// the example in the package documentation.
+		b := append([]byte{1}, `{"limit":10,"sort":[{"field":"name","desc":true}],"filters":[{"field":"status","op":"in","values":["new","done"]}]}`...)
+		tok := GetToken(base64.URLEncoding.EncodeToString(b))
+
+		assert.Equal(t, Token{
+			Limit:   10,
+			Sort:    []SortField{{Field: "name", Desc: true}},
+			Filters: []Filter{{Field: "status", Op: OpIn, Values: []string{"new", "done"}}},
+		}, tok)
+		assert.Equal(t, base64.URLEncoding.EncodeToString(b), tok.String())
+	})
+
+	t.Run("legacy gob tokens", func(t *testing.T) {
+		// the fields of Token before the format was versioned.
+		type legacyToken struct {
+			Limit  int
+			Offset int
+			Params map[string]string
+		}
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(legacyToken{Limit: 5, Offset: 10, Params: map[string]string{"q": "cats"}}))
+
+		tok := GetToken(base64.URLEncoding.EncodeToString(buf.Bytes()))
+		assert.Equal(t, Token{Limit: 5, Offset: 10, Params: map[string]string{"q": "cats"}}, tok)
+	})
+
+	t.Run("unknown versions", func(t *testing.T) {
+		b := append([]byte{2}, `{"limit":10}`...)
+		assert.Equal(t, Token{}, GetToken(base64.URLEncoding.EncodeToString(b)))
+	})
+}
@@ -0,0 +1,122 @@
+package pagetoken
+
+import (
+	"github.com/graymeta/gmkit/postgres"
+)
+
+// Keyset is the position of a page in keyset pagination: the columns the
+// results are ordered by, and the values of those columns in the last row of
+// the previous page. Unlike offset pagination, keyset pagination does not slow
+// down on later pages, and does not skip or repeat rows that are inserted or
+// deleted between pages.
+//
+// Order is always set by KeysetOrder on the server, only the Values are held by
+// the token. Values are decoded from the token as they were encoded in JSON, as
+// a string, json.Number, bool or nil, which postgres converts to the type of
+// the column.
+type Keyset struct {
+	Order  []postgres.OrderPair
+	Values []interface{}
+}
+
+// KeysetOrder switches the token to keyset pagination ordered by the columns.
+// The last column must be unique, such as id, and none may be NULL. See
+// postgres.ByKeyset. A page token holding a number of values other than the
+// number of columns was issued for another order, and is invalid: the token
+// starts over at the first page, and Codec.GetTokenFromQuery returns
+// ErrInvalidToken.
+func KeysetOrder(order ...postgres.OrderPair) TokenOptFn {
+	return func(t Token) Token {
+		var values []interface{}
+		if t.Keyset != nil {
+			values = t.Keyset.Values
+		}
+		if len(values) > 0 && len(values) != len(order) {
+			values = nil
+			t.invalid = true
+		}
+		t.Keyset = &Keyset{Order: order, Values: values}
+		t.Offset = 0
+		return t
+	}
+}
+
+// Where returns the Whereable matching the rows of the page of a keyset token,
+// or nil for the first page or an offset token, or if KeysetOrder was not
+// applied to the token.
+func (t Token) Where() postgres.Whereable {
+	if !t.afterKeyset() {
+		return postgres.All()
+	}
+	return postgres.ByKeyset(t.Keyset.Order, t.Keyset.Values...)
+}
+
+// afterKeyset reports whether the token is for a page after the values of its
+// keyset.
+func (t Token) afterKeyset() bool {
+	return t.Keyset != nil && len(t.Keyset.Values) > 0 && len(t.Keyset.Values) == len(t.Keyset.Order)
+}
+
+// QueryOptions returns the options selecting the page of the token: the order
+// and limit of a keyset token, or the limit and offset of an offset token.
+func (t Token) QueryOptions() postgres.QueryOptions {
+	if t.Keyset != nil {
+		return postgres.QueryOptions{
+			postgres.OrderBy(t.Keyset.Order...),
+			postgres.Limit(int64(t.Limit)),
+		}
+	}
+	return postgres.QueryOptions{
+		postgres.Limit(int64(t.Limit)),
+		postgres.Offset(int64(t.Offset)),
+	}
+}
+
+// WhereFields returns the Whereable of Where for a keyset ordered by the
+// fields of f, given to KeysetOrder as the Col of each pair, rather than by
+// columns.
+func (t Token) WhereFields(f postgres.Fields) postgres.Whereable {
+	if !t.afterKeyset() {
+		return postgres.All()
+	}
+	return f.ByKeyset(t.Keyset.Order, t.Keyset.Values...)
+}
+
+// QueryOptionsFields returns the QueryOptions of QueryOptions for a keyset
+// ordered by the fields of f, given to KeysetOrder as the Col of each pair,
+// rather than by columns. Their Validate returns postgres.ErrUnknownField for an order
+// of fields that are not in f.
+func (t Token) QueryOptionsFields(f postgres.Fields) postgres.QueryOptions {
+	if t.Keyset == nil {
+		return t.QueryOptions()
+	}
+	return postgres.QueryOptions{
+		f.OrderBy(t.Keyset.Order...),
+		postgres.Limit(int64(t.Limit)),
+	}
+}
+
+// NextKeysetToken generates the token for the next page of a keyset token from
+// the number of records returned and the values of the order columns in the
+// last record. If the number of records is less than the limit there is no
+// next page. Keyset tokens only page forwards, so PreviousToken returns "".
+func (t Token) NextKeysetToken(numRecords int, lastValues ...interface{}) string {
+	if t.Keyset == nil || t.Limit == 0 || numRecords < t.Limit {
+		return ""
+	}
+
+	newToken := t
+	newToken.Keyset = &Keyset{
+		Order:  t.Keyset.Order,
+		Values: lastValues,
+	}
+	return newToken.String()
+}
+
+// Keyer is implemented by the rows of keyset paginated results, so that the
+// token of the next page can be generated from the last row, as
+// api.Responder.Page does.
+type Keyer interface {
+	// KeysetValues returns the values of the order columns in the row.
+	KeysetValues(order []postgres.OrderPair) []interface{}
+}
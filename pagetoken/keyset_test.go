@@ -0,0 +1,127 @@
+package pagetoken
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rebinder struct{}
+
+func (rebinder) Rebind(in string) string {
+	return sqlx.Rebind(sqlx.DOLLAR, in)
+}
+
+func TestKeyset(t *testing.T) {
+	order := []postgres.OrderPair{{Col: "created_at", Desc: true}, {Col: "id", Desc: true}}
+
+	t.Run("first page", func(t *testing.T) {
+		tok := GetTokenFromQuery(url.Values{"limit": []string{"10"}, "offset": []string{"20"}}, KeysetOrder(order...))
+
+		assert.Nil(t, tok.Where())
+		opts := tok.QueryOptions()
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, `ORDER BY created_at DESC, id DESC LIMIT 10`, opts.Clause())
+		assert.Empty(t, tok.PreviousToken())
+	})
+
+	t.Run("next page", func(t *testing.T) {
+		tok := GetTokenFromQuery(url.Values{"limit": []string{"10"}}, KeysetOrder(order...))
+		last := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		assert.Empty(t, tok.NextKeysetToken(9, last, "id9"), "no next page after a partial page")
+
+		next := tok.NextKeysetToken(10, last, "id9")
+		require.NotEmpty(t, next)
+
+		// the order is always the server's, the token only holds the values.
+		serverOrder := []postgres.OrderPair{{Col: "name"}, {Col: "id"}}
+		tok = GetTokenFromQuery(url.Values{TokenQueryParam: []string{next}}, KeysetOrder(serverOrder...))
+		query, args, err := postgres.ToWhereClause(rebinder{}, tok.Where())
+		require.NoError(t, err)
+		assert.Equal(t, `WHERE (name, id) > ($1, $2)`, query)
+		// values are passed to postgres as they were encoded in JSON.
+		assert.Equal(t, []interface{}{"2019-01-02T03:04:05Z", "id9"}, args)
+		opts := tok.QueryOptions()
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, `ORDER BY name ASC, id ASC LIMIT 10`, opts.Clause())
+		assert.Empty(t, tok.PreviousToken())
+	})
+
+	t.Run("forged order", func(t *testing.T) {
+		b := append([]byte{formatVersion}, `{"limit":10,"keyset":{"order":[{"field":"password_hash"},{"field":"id"}],"values":["a","id9"]}}`...)
+		forged := base64.URLEncoding.EncodeToString(b)
+
+		tok := GetTokenFromQuery(url.Values{TokenQueryParam: []string{forged}}, KeysetOrder(order...))
+		query, _, err := postgres.ToWhereClause(rebinder{}, tok.Where())
+		require.NoError(t, err)
+		assert.Equal(t, `WHERE (created_at, id) < ($1, $2)`, query)
+	})
+
+	t.Run("token of another order", func(t *testing.T) {
+		next := GetTokenFromQuery(url.Values{"limit": []string{"10"}}, KeysetOrder(postgres.OrderPair{Col: "id"})).NextKeysetToken(10, "id9")
+		require.NotEmpty(t, next)
+
+		// the token starts over at the first page.
+		tok := GetTokenFromQuery(url.Values{TokenQueryParam: []string{next}}, KeysetOrder(order...))
+		assert.Nil(t, tok.Where())
+		assert.Equal(t, order, tok.Keyset.Order)
+
+		codec := NewSignedCodec([]byte("secret"))
+		first, err := codec.GetTokenFromQuery(url.Values{"limit": []string{"10"}}, KeysetOrder(postgres.OrderPair{Col: "id"}))
+		require.NoError(t, err)
+		next = first.NextKeysetToken(10, "id9")
+		_, err = codec.GetTokenFromQuery(url.Values{TokenQueryParam: []string{next}}, KeysetOrder(order...))
+		assert.Equal(t, ErrInvalidToken, err)
+	})
+
+	t.Run("offset tokens", func(t *testing.T) {
+		tok := GetTokenFromQuery(url.Values{"limit": []string{"10"}, "offset": []string{"20"}})
+
+		assert.Nil(t, tok.Where())
+		opts := tok.QueryOptions()
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, "LIMIT 10 OFFSET 20", opts.Clause())
+		assert.Empty(t, tok.NextKeysetToken(10, "id9"))
+	})
+}
+
+func TestKeysetFields(t *testing.T) {
+	fields := postgres.Fields{"created": "created_at", "id": "id"}
+	order := []postgres.OrderPair{{Col: "created", Desc: true}, {Col: "id", Desc: true}}
+
+	tok := GetTokenFromQuery(url.Values{"limit": []string{"10"}}, KeysetOrder(order...))
+	assert.Nil(t, tok.WhereFields(fields))
+	next := tok.NextKeysetToken(10, "2019-01-02T03:04:05Z", "id9")
+
+	tok = GetTokenFromQuery(url.Values{TokenQueryParam: []string{next}}, KeysetOrder(order...))
+	query, args, err := postgres.ToWhereClause(rebinder{}, tok.WhereFields(fields))
+	require.NoError(t, err)
+	assert.Equal(t, `WHERE (created_at, id) < ($1, $2)`, query)
+	assert.Equal(t, []interface{}{"2019-01-02T03:04:05Z", "id9"}, args)
+	opts := tok.QueryOptionsFields(fields)
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, `ORDER BY created_at DESC, id DESC LIMIT 10`, opts.Clause())
+
+	// an order of fields that are not allowed is an error.
+	tok = GetTokenFromQuery(url.Values{TokenQueryParam: []string{next}}, KeysetOrder(postgres.OrderPair{Col: "password_hash"}, postgres.OrderPair{Col: "id"}))
+	_, _, err = postgres.ToWhereClause(rebinder{}, tok.WhereFields(fields))
+	assert.True(t, errors.Is(err, postgres.ErrUnknownField))
+	err = tok.QueryOptionsFields(fields).Validate()
+	assert.True(t, errors.Is(err, postgres.ErrUnknownField))
+
+	// offset tokens are unaffected.
+	tok = GetTokenFromQuery(url.Values{"limit": []string{"10"}, "offset": []string{"20"}})
+	assert.Nil(t, tok.WhereFields(fields))
+	opts = tok.QueryOptionsFields(fields)
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, "LIMIT 10 OFFSET 20", opts.Clause())
+}
@@ -1,9 +1,7 @@
 package pagetoken
 
 import (
-	"bytes"
 	"encoding/base64"
-	"encoding/gob"
 	"net/url"
 	"strconv"
 )
@@ -26,6 +24,21 @@ type Token struct {
 	Offset   int
 	limitSet bool
 	Params   map[string]string
+
+	// Sort and Filters are the typed sort and filter params of the results.
+	Sort    []SortField
+	Filters []Filter
+
+	// Keyset is the position of the page in keyset pagination, which is used
+	// instead of Offset when set.
+	Keyset *Keyset
+
+	// invalid is set by an option that found the page token to be invalid.
+	invalid bool
+
+	// codec encodes the token and the tokens derived from it, when it was
+	// decoded by a Codec.
+	codec *Codec
 }
 
 // NextToken generates new token from existing token and number of records
@@ -81,13 +94,16 @@ func (t Token) GetParamAsBool(key string) bool {
 }
 
 // String implements the fmt.Stringer interfaces to get a string representation
-// of the TokenDeets.
+// of the TokenDeets. Tokens obtained from a Codec are encoded by the Codec.
 func (t Token) String() string {
-	var buf bytes.Buffer
-	if err := gob.NewEncoder(&buf).Encode(t); err != nil {
+	if t.codec != nil {
+		return t.codec.Encode(t)
+	}
+	b, err := t.marshal()
+	if err != nil {
 		return ""
 	}
-	return base64.URLEncoding.EncodeToString(buf.Bytes())
+	return base64.URLEncoding.EncodeToString(b)
 }
 
 // TokenOptFn is a functional option for setting token deets with sane bounds.
@@ -140,12 +156,22 @@ func Param(key, val string) TokenOptFn {
 	}
 }
 
-// GetTokenFromQuery provides the Token from the url.Values.
+// GetTokenFromQuery provides the Token from the url.Values. It never fails: a
+// page token query param that cannot be decoded gives an empty Token, and the
+// keyset values of a token that an option such as KeysetOrder finds invalid
+// are dropped, so in either case the listing starts over from the first page.
+// Use the GetTokenFromQuery of a Codec to reject such tokens with
+// ErrInvalidToken instead.
 func GetTokenFromQuery(q url.Values, opts ...TokenOptFn) Token {
-	pageToken := GetToken(q.Get("page-token"))
+	return fromQuery(GetToken(q.Get(TokenQueryParam)), q, opts...)
+}
+
+// fromQuery applies the limit and offset query params, and then the options,
+// to the token decoded from the page token query param.
+func fromQuery(pageToken Token, q url.Values, opts ...TokenOptFn) Token {
 	pageToken.limitSet = true
 	if pageToken.Limit == 0 {
-		limit, err := strconv.ParseInt(q.Get("limit"), 10, 64)
+		limit, err := strconv.ParseInt(q.Get(LimitQueryParam), 10, 64)
 		if err == nil {
 			pageToken.Limit = int(limit)
 		} else {
@@ -178,9 +204,8 @@ func GetToken(token string) Token {
 		return Token{}
 	}
 
-	var newTok Token
-	dec := gob.NewDecoder(bytes.NewReader(b))
-	if err := dec.Decode(&newTok); err != nil {
+	newTok, err := unmarshalToken(b)
+	if err != nil {
 		return Token{}
 	}
 
@@ -65,12 +65,7 @@ func Test_GetTokenDeets(t *testing.T) {
 					Offset: tt.offset + tt.limit,
 				}
 
-				var newBuf bytes.Buffer
-				err = gob.NewEncoder(&newBuf).Encode(nextTok)
-				require.NoError(t, err)
-
-				expectedToken := base64.URLEncoding.EncodeToString([]byte(newBuf.Bytes()))
-				assert.Equal(t, nextTok.String(), expectedToken)
+				assert.Equal(t, nextTok, GetToken(newToken.NextToken(tt.limit, TotalTODO)))
 			}
 			t.Run(fmt.Sprintf("limit-%d offset-%d", tt.limit, tt.offset), fn)
 		}
@@ -453,12 +448,12 @@ func ExampleToken_first() {
 	// Output:
 	// 5
 	// 0
-	// NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAABf-CAQoA
+	// AXsibGltaXQiOjV9
 }
 
 func ExampleToken_second() {
 	params := url.Values{
-		TokenQueryParam: []string{"NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAABf-CAQoA"},
+		TokenQueryParam: []string{"AXsibGltaXQiOjV9"},
 	}
 
 	token := GetTokenFromQuery(params)
@@ -469,7 +464,7 @@ func ExampleToken_second() {
 	// Output:
 	// 5
 	// 0
-	// NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAABf-CAQoA
+	// AXsibGltaXQiOjV9
 }
 
 func ExampleToken_NextToken_first() {
@@ -490,14 +485,15 @@ func ExampleToken_NextToken_first() {
 	fmt.Println(token.Offset)
 	fmt.Println(token.String())
 	// Output:
-	// NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAAB_-CAQoBCgA=
+	// AXsibGltaXQiOjUsIm9mZnNldCI6NX0=
 	// 5
 	// 5
-	// NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAAB_-CAQoBCgA=
+	// AXsibGltaXQiOjUsIm9mZnNldCI6NX0=
 }
 
 func ExampleToken_NextToken_second() {
-	// strToken below is equivalent to setting limit=5 and offset=0
+	// strToken below is a legacy gob token equivalent to setting limit=5 and
+	// offset=0, which is still accepted.
 	strToken := "NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAABf-CAQoA"
 	params := url.Values{
 		TokenQueryParam: []string{strToken},
@@ -515,10 +511,10 @@ func ExampleToken_NextToken_second() {
 	fmt.Println(token.Offset)
 	fmt.Println(token.String())
 	// Output:
-	// NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAAB_-CAQoBCgA=
+	// AXsibGltaXQiOjUsIm9mZnNldCI6NX0=
 	// 5
 	// 5
-	// NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAAB_-CAQoBCgA=
+	// AXsibGltaXQiOjUsIm9mZnNldCI6NX0=
 }
 
 func ExampleToken_NextToken_third() {
@@ -537,7 +533,8 @@ func ExampleToken_NextToken_third() {
 }
 
 func ExampleToken_NextToken_fourth() {
-	// strToken below is equivalent to setting limit=5 and offset=0
+	// strToken below is a legacy gob token equivalent to setting limit=5 and
+	// offset=0, which is still accepted.
 	strToken := "NP-BAwEBBVRva2VuAf-CAAEDAQVMaW1pdAEEAAEGT2Zmc2V0AQQAAQZQYXJhbXMB_4QAAAAh_4MEAQERbWFwW3N0cmluZ11zdHJpbmcB_4QAAQwBDAAABf-CAQoA"
 	params := url.Values{
 		TokenQueryParam: []string{strToken},
@@ -0,0 +1,79 @@
+package pagetoken
+
+import (
+	"github.com/graymeta/gmkit/postgres"
+)
+
+// SortField is a field the results are sorted by.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// FilterOp is the comparison a Filter makes.
+type FilterOp string
+
+// The comparisons a Filter can make.
+const (
+	OpEq   FilterOp = "eq"
+	OpNe   FilterOp = "ne"
+	OpLt   FilterOp = "lt"
+	OpLte  FilterOp = "lte"
+	OpGt   FilterOp = "gt"
+	OpGte  FilterOp = "gte"
+	OpIn   FilterOp = "in"
+	OpLike FilterOp = "like"
+)
+
+// Filter restricts the results to those whose field compares to the values.
+// Every op but OpIn takes a single value.
+type Filter struct {
+	Field  string
+	Op     FilterOp
+	Values []string
+}
+
+// Sort sorts the results by the fields, unless the page token already holds a
+// sort.
+func Sort(fields ...SortField) TokenOptFn {
+	return func(t Token) Token {
+		if len(t.Sort) > 0 {
+			return t
+		}
+		t.Sort = fields
+		return t
+	}
+}
+
+// FilterBy adds a filter on a field that is not already filtered by the page
+// token.
+func FilterBy(field string, op FilterOp, values ...string) TokenOptFn {
+	return func(t Token) Token {
+		if _, ok := t.GetFilter(field); ok {
+			return t
+		}
+		t.Filters = append(t.Filters, Filter{Field: field, Op: op, Values: values})
+		return t
+	}
+}
+
+// GetFilter returns the filter on the field, if there is one.
+func (t Token) GetFilter(field string) (Filter, bool) {
+	for _, f := range t.Filters {
+		if f.Field == field {
+			return f, true
+		}
+	}
+	return Filter{}, false
+}
+
+// OrderPairs returns the sort of the token as postgres order pairs, using the
+// sort fields as the columns. The fields come from clients, so map them to
+// columns with postgres.Fields.OrderBy.
+func (t Token) OrderPairs() []postgres.OrderPair {
+	pairs := make([]postgres.OrderPair, len(t.Sort))
+	for i, s := range t.Sort {
+		pairs[i] = postgres.OrderPair{Col: s.Field, Desc: s.Desc}
+	}
+	return pairs
+}
@@ -0,0 +1,46 @@
+package pagetoken
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedParams(t *testing.T) {
+	t.Run("options apply to a new token", func(t *testing.T) {
+		tok := GetTokenFromQuery(url.Values{},
+			Sort(SortField{Field: "name"}),
+			FilterBy("status", OpEq, "new"),
+		)
+
+		assert.Equal(t, []postgres.OrderPair{{Col: "name"}}, tok.OrderPairs())
+		f, ok := tok.GetFilter("status")
+		assert.True(t, ok)
+		assert.Equal(t, Filter{Field: "status", Op: OpEq, Values: []string{"new"}}, f)
+	})
+
+	t.Run("options do not override the page token", func(t *testing.T) {
+		prev := Token{
+			Limit:   10,
+			Sort:    []SortField{{Field: "created_at", Desc: true}},
+			Filters: []Filter{{Field: "status", Op: OpIn, Values: []string{"new", "done"}}},
+		}
+
+		tok := GetTokenFromQuery(url.Values{TokenQueryParam: []string{prev.String()}},
+			Sort(SortField{Field: "name"}),
+			FilterBy("status", OpEq, "new"),
+			FilterBy("owner", OpEq, "me"),
+		)
+
+		assert.Equal(t, []postgres.OrderPair{{Col: "created_at", Desc: true}}, tok.OrderPairs())
+		f, _ := tok.GetFilter("status")
+		assert.Equal(t, OpIn, f.Op)
+		_, ok := tok.GetFilter("owner")
+		assert.True(t, ok)
+		_, ok = tok.GetFilter("missing")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ErrStaleFencingToken is returned by CheckFencingToken when a write was
+// rejected because a newer fencing token has already been written. It exhibits
+// the Conflict behavior.
+var ErrStaleFencingToken error = staleFencingTokenErr{}
+
+type staleFencingTokenErr struct{}
+
+func (staleFencingTokenErr) Error() string {
+	return "stale fencing token, a newer lock holder has written this resource"
+}
+
+func (staleFencingTokenErr) Conflict() bool {
+	return true
+}
+
+type fencingToken struct {
+	key   string
+	token int64
+}
+
+func (f fencingToken) Clause(rb Rebinder) (string, []interface{}, error) {
+	key, err := identifier(f.key)
+	if err != nil {
+		return "", nil, err
+	}
+	query := fmt.Sprintf("(%s IS NULL OR %s <= %s)", key, key, rb.Rebind("?"))
+	return query, []interface{}{f.token}, nil
+}
+
+// ByFencingToken creates a `({column} IS NULL OR {column} <= ?)` argument for a
+// Where clause. Combined with an UPDATE that sets column to token, it ensures
+// a write made under a lock is rejected once a newer holder of the lock has
+// written the row. Use CheckFencingToken on the result of the write to detect
+// the rejection.
+func ByFencingToken(column string, token int64) Whereable {
+	return fencingToken{
+		key:   column,
+		token: token,
+	}
+}
+
+// CheckFencingToken returns ErrStaleFencingToken if no rows were affected by a
+// write guarded by ByFencingToken.
+func CheckFencingToken(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrStaleFencingToken
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package postgres_test
+
+import (
+	"errors"
+	"testing"
+
+	gmerrors "github.com/graymeta/gmkit/errors"
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFencingToken(t *testing.T) {
+	t.Run("rows affected", func(t *testing.T) {
+		assert.NoError(t, postgres.CheckFencingToken(result{rows: 1}))
+	})
+
+	t.Run("stale token", func(t *testing.T) {
+		err := postgres.CheckFencingToken(result{rows: 0})
+		assert.Equal(t, postgres.ErrStaleFencingToken, err)
+
+		cErr, ok := err.(gmerrors.Conflicter)
+		if assert.True(t, ok) {
+			assert.True(t, cErr.Conflict())
+		}
+	})
+
+	t.Run("rows affected error", func(t *testing.T) {
+		rowsErr := errors.New("not supported")
+		assert.Equal(t, rowsErr, postgres.CheckFencingToken(result{err: rowsErr}))
+	})
+}
+
+type result struct {
+	rows int64
+	err  error
+}
+
+func (result) LastInsertId() (int64, error) { return 0, nil }
+
+func (r result) RowsAffected() (int64, error) { return r.rows, r.err }
@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	gmerrors "github.com/graymeta/gmkit/errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrInvalidIdentifier is returned by the Clause of a Whereable, the
+	// Validate of QueryOptions, or the Query of a statement, whose column or
+	// table is not a valid identifier. It is caused by a column that usually
+	// comes from a field a client sent, such as a sort field, so it maps to a
+	// 400 response.
+	ErrInvalidIdentifier error = &gmerrors.HTTPErr{Msg: "invalid identifier", Code: http.StatusBadRequest}
+
+	// ErrUnknownField is returned by the Clause of a Whereable, or the Validate
+	// of QueryOptions, made by Fields for a field that is not in the Fields.
+	ErrUnknownField error = &gmerrors.HTTPErr{Msg: "unknown field", Code: http.StatusBadRequest}
+)
+
+// maxIdentifierLength is the length postgres truncates identifiers to.
+const maxIdentifierLength = 63
+
+var (
+	identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+	identifierPart    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// identifier returns the name of a column or table, optionally qualified such
+// as `items.id`, as is if it is lower case letters, digits and underscores not
+// starting with a digit, or ErrInvalidIdentifier for any other name.
+//
+// Names are used unquoted, as they were before they were checked, so they keep
+// matching the same columns. Mixed case names, which postgres folds to lower
+// case unless quoted, and expressions are rejected, and must be given with Raw
+// or a Raw OrderPair instead.
+func identifier(name string) (string, error) {
+	if !identifierPattern.MatchString(name) {
+		return "", fmt.Errorf("%w %q", ErrInvalidIdentifier, name)
+	}
+	for _, p := range strings.Split(name, ".") {
+		if len(p) > maxIdentifierLength {
+			return "", fmt.Errorf("%w %q", ErrInvalidIdentifier, name)
+		}
+	}
+	return name, nil
+}
+
+// QuoteIdentifier returns the name of a column or table, optionally qualified
+// such as `items.id`, quoted with pq.QuoteIdentifier. Names are letters,
+// digits and underscores, and do not start with a digit; any other name
+// returns ErrInvalidIdentifier. Quoted identifiers are case sensitive, so the
+// name must match the case of the column, which is lower case unless it was
+// created quoted.
+//
+// The Whereables, QueryOptions and statements of this package do not quote
+// their identifiers, see Whereable.
+func QuoteIdentifier(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		if len(p) > maxIdentifierLength || !identifierPart.MatchString(p) {
+			return "", fmt.Errorf("%w %q", ErrInvalidIdentifier, name)
+		}
+		parts[i] = pq.QuoteIdentifier(p)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// Fields is an allowlist mapping the field names of an API, such as the sort
+// fields and filters of a page token, to the columns they are stored in. Its
+// Whereable and QueryOption constructors make those of this package from the
+// column of a field, and return ErrUnknownField from Clause, or from Validate
+// for a QueryOption, for any other field, so that fields taken from clients only
+// ever select known columns:
+//
+//	var itemFields = postgres.Fields{"name": "name", "created": "created_at"}
+//
+//	opts := postgres.QueryOptions{itemFields.OrderBy(token.OrderPairs()...)}
+//	if err := opts.Validate(); err != nil {
+//		return err
+//	}
+type Fields map[string]string
+
+// Column returns the column of the field, or ErrUnknownField.
+func (f Fields) Column(field string) (string, error) {
+	col, ok := f[field]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownField, field)
+	}
+	return col, nil
+}
+
+// ByFieldEquals creates a `{column} = ?` argument for a Where clause.
+func (f Fields) ByFieldEquals(field string, value interface{}) Whereable {
+	col, err := f.Column(field)
+	if err != nil {
+		return invalid{err: err}
+	}
+	return ByFieldEquals(col, value)
+}
+
+// ByFieldNotEquals creates a `{column} != ?` argument for a Where clause.
+func (f Fields) ByFieldNotEquals(field string, value interface{}) Whereable {
+	col, err := f.Column(field)
+	if err != nil {
+		return invalid{err: err}
+	}
+	return ByFieldNotEquals(col, value)
+}
+
+// ByFieldIn creates a `{column} IN (?, ?, ...)` argument for a Where clause.
+func (f Fields) ByFieldIn(field string, values ...interface{}) Whereable {
+	col, err := f.Column(field)
+	if err != nil {
+		return invalid{err: err}
+	}
+	return ByFieldIn(col, values...)
+}
+
+// Like creates a where clause that uses a like matcher on the column.
+func (f Fields) Like(field string, val string) Whereable {
+	col, err := f.Column(field)
+	if err != nil {
+		return invalid{err: err}
+	}
+	return Like(col, val)
+}
+
+// ByKeyset creates a Where clause matching the rows after the row with the
+// given values of the columns of the fields, given as the Col of each pair. See
+// ByKeyset.
+func (f Fields) ByKeyset(order []OrderPair, values ...interface{}) Whereable {
+	cols, err := f.columns(order)
+	if err != nil {
+		return invalid{err: err}
+	}
+	return ByKeyset(cols, values...)
+}
+
+// OrderBy returns a QueryOption that applies an ORDER BY of the columns of the
+// fields, given as the Col of each pair.
+func (f Fields) OrderBy(fields ...OrderPair) QueryOption {
+	cols, err := f.columns(fields)
+	if err != nil {
+		return orderBy{err: err}
+	}
+	return OrderBy(cols...)
+}
+
+// columns maps the fields of the pairs to their columns.
+func (f Fields) columns(fields []OrderPair) ([]OrderPair, error) {
+	cols := make([]OrderPair, len(fields))
+	for i, p := range fields {
+		col, err := f.Column(p.Col)
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = OrderPair{Col: col, Desc: p.Desc}
+	}
+	return cols, nil
+}
+
+// invalid is a Whereable that could not be made, which returns its error from
+// Clause.
+type invalid struct {
+	err error
+}
+
+func (i invalid) Clause(rb Rebinder) (string, []interface{}, error) {
+	return "", nil, i.err
+}
@@ -0,0 +1,173 @@
+package postgres_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		valid    bool
+	}{
+		{"id", `"id"`, true},
+		{"created_at", `"created_at"`, true},
+		{"_Name2", `"_Name2"`, true},
+		{"items.id", `"items"."id"`, true},
+		{"", "", false},
+		{"2id", "", false},
+		{"items.", "", false},
+		{"id; DROP TABLE items", "", false},
+		{`id"`, "", false},
+		{"lower(name)", "", false},
+		{"a234567890123456789012345678901234567890123456789012345678901234", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quoted, err := postgres.QuoteIdentifier(test.name)
+			if !test.valid {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, postgres.ErrInvalidIdentifier))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, quoted)
+		})
+	}
+}
+
+func TestInvalidIdentifiers(t *testing.T) {
+	for _, key := range []string{"id = id OR 1", "createdAt", "Items.id", "lower(name)", "a.b.c"} {
+		t.Run(key, func(t *testing.T) {
+			testInvalidIdentifier(t, key)
+		})
+	}
+}
+
+func testInvalidIdentifier(t *testing.T, key string) {
+	tests := []struct {
+		name      string
+		whereable postgres.Whereable
+	}{
+		{"ByFieldEquals", postgres.ByFieldEquals(key, "one")},
+		{"ByFieldNotEquals", postgres.ByFieldNotEquals(key, "one")},
+		{"ByFieldIn", postgres.ByFieldIn(key, "one")},
+		{"Like", postgres.Like(key, "one")},
+		{"ByFencingToken", postgres.ByFencingToken(key, 3)},
+		{"ByKeyset", postgres.ByKeyset([]postgres.OrderPair{{Col: "id"}, {Col: key}}, "one", "two")},
+		{"And", postgres.And(postgres.ByID("one"), postgres.ByFieldEquals(key, "two"))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := postgres.ToWhereClause(&rebinder{}, test.whereable)
+			assert.True(t, errors.Is(err, postgres.ErrInvalidIdentifier))
+		})
+	}
+
+	opts := postgres.QueryOptions{postgres.Limit(10), postgres.OrderBy(postgres.OrderPair{Col: "id"}, postgres.OrderPair{Col: key})}
+	assert.True(t, errors.Is(opts.Validate(), postgres.ErrInvalidIdentifier))
+	assert.Equal(t, "LIMIT 10", opts.Clause())
+}
+
+func TestFields(t *testing.T) {
+	fields := postgres.Fields{"name": "name", "created": "items.created_at"}
+
+	tests := []struct {
+		name          string
+		expectedQuery string
+		expectedArgs  []interface{}
+		whereable     postgres.Whereable
+	}{
+		{
+			"ByFieldEquals",
+			`WHERE items.created_at = $1`,
+			[]interface{}{"2019-01-01"},
+			fields.ByFieldEquals("created", "2019-01-01"),
+		},
+		{
+			"ByFieldNotEquals",
+			`WHERE name != $1`,
+			[]interface{}{"one"},
+			fields.ByFieldNotEquals("name", "one"),
+		},
+		{
+			"ByFieldIn",
+			`WHERE name IN ($1, $2)`,
+			[]interface{}{"one", "two"},
+			fields.ByFieldIn("name", "one", "two"),
+		},
+		{
+			"Like",
+			`WHERE name LIKE $1`,
+			[]interface{}{"o%"},
+			fields.Like("name", "o%"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, args, err := postgres.ToWhereClause(&rebinder{}, test.whereable)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedQuery, query)
+			assert.Equal(t, test.expectedArgs, args)
+		})
+	}
+
+	order := fields.OrderBy(postgres.OrderPair{Col: "created", Desc: true}, postgres.OrderPair{Col: "name"})
+	require.NoError(t, postgres.QueryOptions{order}.Validate())
+	assert.Equal(t, `ORDER BY items.created_at DESC, name ASC`, order.Clause())
+
+	unknown := []postgres.Whereable{
+		fields.ByFieldEquals("id", "one"),
+		fields.ByFieldNotEquals("id", "one"),
+		fields.ByFieldIn("id", "one"),
+		fields.Like("id", "one"),
+	}
+	for _, w := range unknown {
+		_, _, err := postgres.ToWhereClause(&rebinder{}, w)
+		assert.True(t, errors.Is(err, postgres.ErrUnknownField))
+		assert.EqualError(t, err, `unknown field "id"`)
+	}
+
+	err := postgres.QueryOptions{postgres.Limit(10), fields.OrderBy(postgres.OrderPair{Col: "id"})}.Validate()
+	assert.True(t, errors.Is(err, postgres.ErrUnknownField))
+}
+
+func TestRaw(t *testing.T) {
+	query, args, err := postgres.ToWhereClause(&rebinder{}, postgres.And(
+		postgres.Raw("lower(name) = ?", "felix"),
+		postgres.Raw(`"createdAt" > ? AND data->>'kind' IN (?, ?)`, "2019-01-01", "a", "b"),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, `WHERE (lower(name) = $1 AND "createdAt" > $2 AND data->>'kind' IN ($3, $4))`, query)
+	assert.Equal(t, []interface{}{"felix", "2019-01-01", "a", "b"}, args)
+
+	order := []postgres.OrderPair{{Col: "lower(name)", Raw: true}, {Col: "created_at NULLS LAST", Desc: true, Raw: true}, {Col: "id"}}
+	opts := postgres.QueryOptions{postgres.OrderBy(order...)}
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, `ORDER BY lower(name) ASC, created_at DESC NULLS LAST, id ASC`, opts.Clause())
+
+	query, _, err = postgres.ToWhereClause(&rebinder{}, postgres.ByKeyset(order, "felix", "2019-01-01", "i1"))
+	require.NoError(t, err)
+	assert.Equal(t, `WHERE ((lower(name) > $1) OR (lower(name) = $2 AND created_at < $3) OR (lower(name) = $4 AND created_at = $5 AND id > $6))`, query)
+}
+
+func TestFieldsByKeyset(t *testing.T) {
+	fields := postgres.Fields{"created": "created_at", "id": "items.id"}
+
+	query, args, err := postgres.ToWhereClause(&rebinder{}, fields.ByKeyset([]postgres.OrderPair{{Col: "created"}, {Col: "id"}}, "2019-01-01", "i1"))
+	require.NoError(t, err)
+	assert.Equal(t, `WHERE (created_at, items.id) > ($1, $2)`, query)
+	assert.Equal(t, []interface{}{"2019-01-01", "i1"}, args)
+
+	_, _, err = postgres.ToWhereClause(&rebinder{}, fields.ByKeyset([]postgres.OrderPair{{Col: "name"}}, "a"))
+	assert.True(t, errors.Is(err, postgres.ErrUnknownField))
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrKeysetMismatch is returned by the Clause of a ByKeyset Whereable when the
+// number of values does not match the number of columns.
+var ErrKeysetMismatch = errors.New("keyset values do not match the order columns")
+
+type keyset struct {
+	order  []OrderPair
+	values []interface{}
+}
+
+func (k keyset) Clause(rb Rebinder) (string, []interface{}, error) {
+	if len(k.order) == 0 || len(k.order) != len(k.values) {
+		return "", nil, ErrKeysetMismatch
+	}
+
+	cols := make([]string, len(k.order))
+	for i, o := range k.order {
+		col, err := o.column()
+		if err != nil {
+			return "", nil, err
+		}
+		cols[i] = col
+	}
+
+	desc := k.order[0].Desc
+	sameDirection := true
+	for _, o := range k.order[1:] {
+		sameDirection = sameDirection && o.Desc == desc
+	}
+
+	// a row comparison can use a composite index on the columns, but only
+	// compares every column in the same direction.
+	if sameDirection {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(k.values)), ", ")
+		query := fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), comparison(desc), placeholders)
+		return rb.Rebind(query), k.values, nil
+	}
+
+	var (
+		ors  []string
+		args []interface{}
+	)
+	for i, o := range k.order {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = ?", cols[j]))
+			args = append(args, k.values[j])
+		}
+		ands = append(ands, fmt.Sprintf("%s %s ?", cols[i], comparison(o.Desc)))
+		args = append(args, k.values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return rb.Rebind("(" + strings.Join(ors, " OR ") + ")"), args, nil
+}
+
+func comparison(desc bool) string {
+	if desc {
+		return "<"
+	}
+	return ">"
+}
+
+// ByKeyset creates a Where clause matching the rows after the row with the
+// given values of the order columns, when ordered by OrderBy(order...). When
+// every column is ordered in the same direction this is a row comparison such
+// as `(created_at, id) > (?, ?)`. The order columns must not be NULL, and must
+// end with a unique column such as id so that no two rows have the same
+// values.
+func ByKeyset(order []OrderPair, values ...interface{}) Whereable {
+	return keyset{
+		order:  order,
+		values: values,
+	}
+}
@@ -0,0 +1,327 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var errNoTable = errors.New("no table specified")
+var errNoColumnsSet = errors.New("no columns set")
+
+// unbound is a Rebinder that keeps the ? placeholders of the clauses of a
+// statement, so that the statement is rebound as a whole and its placeholders
+// are numbered in order.
+type unbound struct{}
+
+func (unbound) Rebind(q string) string {
+	return sqlx.Rebind(sqlx.QUESTION, q)
+}
+
+// SelectStmt builds a SELECT statement. Each method returns a new statement,
+// so a statement may be shared and extended by several queries.
+type SelectStmt struct {
+	table string
+	cols  []column
+	joins []join
+	where Whereable
+	opts  QueryOptions
+}
+
+type column struct {
+	name string
+	raw  bool
+}
+
+type join struct {
+	kind, table, on string
+}
+
+// Select starts a SELECT statement of the table, which may be followed by an
+// alias, such as `items i`. Without Columns, every column is selected.
+//
+// Table names, aliases and columns are checked as those of a Whereable are, and
+// Query returns ErrInvalidIdentifier for any that is not a valid identifier.
+func Select(table string) SelectStmt {
+	return SelectStmt{table: table}
+}
+
+// Columns adds columns, optionally qualified such as `i.id`, to the statement.
+func (s SelectStmt) Columns(cols ...string) SelectStmt {
+	for _, c := range cols {
+		s.cols = append(s.cols[:len(s.cols):len(s.cols)], column{name: c})
+	}
+	return s
+}
+
+// RawColumns adds select expressions, such as `count(*)` or
+// `lower(name) AS name`, to the statement. They are used as is, so must never
+// be taken from clients.
+func (s SelectStmt) RawColumns(exprs ...string) SelectStmt {
+	for _, e := range exprs {
+		s.cols = append(s.cols[:len(s.cols):len(s.cols)], column{name: e, raw: true})
+	}
+	return s
+}
+
+// Join adds a `JOIN {table} ON {on}` to the statement. The table may be
+// followed by an alias, and on is used as is, so must never be taken from
+// clients.
+func (s SelectStmt) Join(table, on string) SelectStmt {
+	return s.join("JOIN", table, on)
+}
+
+// LeftJoin adds a `LEFT JOIN {table} ON {on}` to the statement, as Join does.
+func (s SelectStmt) LeftJoin(table, on string) SelectStmt {
+	return s.join("LEFT JOIN", table, on)
+}
+
+func (s SelectStmt) join(kind, table, on string) SelectStmt {
+	s.joins = append(s.joins[:len(s.joins):len(s.joins)], join{kind: kind, table: table, on: on})
+	return s
+}
+
+// Where filters the rows of the statement by w, in addition to any Whereable
+// it was already filtered by.
+func (s SelectStmt) Where(w Whereable) SelectStmt {
+	s.where = andWhere(s.where, w)
+	return s
+}
+
+// Options adds the ORDER BY, LIMIT and OFFSET of the statement.
+func (s SelectStmt) Options(opts ...QueryOption) SelectStmt {
+	s.opts = append(s.opts[:len(s.opts):len(s.opts)], opts...)
+	return s
+}
+
+// Query returns the statement and its args, rebound by rb.
+func (s SelectStmt) Query(rb Rebinder) (string, []interface{}, error) {
+	if s.table == "" {
+		return "", nil, errNoTable
+	}
+	table, err := tableName(s.table)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cols := "*"
+	if len(s.cols) > 0 {
+		names := make([]string, len(s.cols))
+		for i, c := range s.cols {
+			names[i] = c.name
+			if c.raw {
+				continue
+			}
+			if names[i], err = identifier(c.name); err != nil {
+				return "", nil, err
+			}
+		}
+		cols = strings.Join(names, ", ")
+	}
+	joins := make([]string, len(s.joins))
+	for i, j := range s.joins {
+		joinTable, err := tableName(j.table)
+		if err != nil {
+			return "", nil, err
+		}
+		joins[i] = fmt.Sprintf("%s %s ON %s", j.kind, joinTable, j.on)
+	}
+	where, args, err := ToWhereClause(unbound{}, s.where)
+	if err != nil {
+		return "", nil, err
+	}
+	// Clause sorts the options, which may be shared with other statements.
+	opts := append(QueryOptions(nil), s.opts...)
+	if err := opts.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	parts := []string{"SELECT", cols, "FROM", table}
+	parts = append(parts, joins...)
+	parts = append(parts, where, opts.Clause())
+	return rb.Rebind(joinParts(parts)), args, nil
+}
+
+type assignment struct {
+	col string
+	val interface{}
+}
+
+// UpdateStmt builds an UPDATE statement. Each method returns a new statement,
+// so a statement may be shared and extended by several queries.
+type UpdateStmt struct {
+	table     string
+	sets      []assignment
+	where     Whereable
+	returning []string
+}
+
+// Update starts an UPDATE statement of the table. The table and columns are
+// checked as those of a Whereable are, and Query returns ErrInvalidIdentifier
+// for any that is not a valid identifier.
+func Update(table string) UpdateStmt {
+	return UpdateStmt{table: table}
+}
+
+// Set sets the column to val, in the order columns are set.
+func (u UpdateStmt) Set(col string, val interface{}) UpdateStmt {
+	u.sets = append(u.sets[:len(u.sets):len(u.sets)], assignment{col: col, val: val})
+	return u
+}
+
+// Where filters the rows of the statement by w, in addition to any Whereable
+// it was already filtered by. Without a Whereable, every row is updated.
+func (u UpdateStmt) Where(w Whereable) UpdateStmt {
+	u.where = andWhere(u.where, w)
+	return u
+}
+
+// Returning adds a RETURNING of the columns of the updated rows.
+func (u UpdateStmt) Returning(cols ...string) UpdateStmt {
+	u.returning = append(u.returning[:len(u.returning):len(u.returning)], cols...)
+	return u
+}
+
+// Query returns the statement and its args, rebound by rb.
+func (u UpdateStmt) Query(rb Rebinder) (string, []interface{}, error) {
+	if u.table == "" {
+		return "", nil, errNoTable
+	}
+	if len(u.sets) == 0 {
+		return "", nil, errNoColumnsSet
+	}
+	table, err := identifier(u.table)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sets := make([]string, 0, len(u.sets))
+	args := make([]interface{}, 0, len(u.sets))
+	for _, s := range u.sets {
+		col, err := identifier(s.col)
+		if err != nil {
+			return "", nil, err
+		}
+		sets = append(sets, col+" = ?")
+		args = append(args, s.val)
+	}
+	where, whereArgs, err := ToWhereClause(unbound{}, u.where)
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, whereArgs...)
+	ret, err := returning(u.returning)
+	if err != nil {
+		return "", nil, err
+	}
+
+	parts := []string{"UPDATE", table, "SET", strings.Join(sets, ", "), where, ret}
+	return rb.Rebind(joinParts(parts)), args, nil
+}
+
+// DeleteStmt builds a DELETE statement. Each method returns a new statement,
+// so a statement may be shared and extended by several queries.
+type DeleteStmt struct {
+	table     string
+	where     Whereable
+	returning []string
+}
+
+// Delete starts a DELETE statement of the table. The table and columns are
+// checked as those of a Whereable are, and Query returns ErrInvalidIdentifier
+// for any that is not a valid identifier.
+func Delete(table string) DeleteStmt {
+	return DeleteStmt{table: table}
+}
+
+// Where filters the rows of the statement by w, in addition to any Whereable
+// it was already filtered by. Without a Whereable, every row is deleted.
+func (d DeleteStmt) Where(w Whereable) DeleteStmt {
+	d.where = andWhere(d.where, w)
+	return d
+}
+
+// Returning adds a RETURNING of the columns of the deleted rows.
+func (d DeleteStmt) Returning(cols ...string) DeleteStmt {
+	d.returning = append(d.returning[:len(d.returning):len(d.returning)], cols...)
+	return d
+}
+
+// Query returns the statement and its args, rebound by rb.
+func (d DeleteStmt) Query(rb Rebinder) (string, []interface{}, error) {
+	if d.table == "" {
+		return "", nil, errNoTable
+	}
+	table, err := identifier(d.table)
+	if err != nil {
+		return "", nil, err
+	}
+
+	where, args, err := ToWhereClause(unbound{}, d.where)
+	if err != nil {
+		return "", nil, err
+	}
+	ret, err := returning(d.returning)
+	if err != nil {
+		return "", nil, err
+	}
+
+	parts := []string{"DELETE FROM", table, where, ret}
+	return rb.Rebind(joinParts(parts)), args, nil
+}
+
+func andWhere(current, w Whereable) Whereable {
+	if current == nil {
+		return w
+	}
+	if w == nil {
+		return current
+	}
+	return And(current, w)
+}
+
+func returning(cols []string) (string, error) {
+	if len(cols) == 0 {
+		return "", nil
+	}
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		var err error
+		if names[i], err = identifier(c); err != nil {
+			return "", err
+		}
+	}
+	return "RETURNING " + strings.Join(names, ", "), nil
+}
+
+// tableName checks a table name, optionally followed by an alias such as
+// `items i` or `items AS i`.
+func tableName(table string) (string, error) {
+	parts := strings.Fields(table)
+	if len(parts) == 3 && strings.EqualFold(parts[1], "AS") {
+		parts = []string{parts[0], parts[2]}
+	}
+	if len(parts) == 0 || len(parts) > 2 {
+		return "", fmt.Errorf("%w %q", ErrInvalidIdentifier, table)
+	}
+	for i, p := range parts {
+		var err error
+		if parts[i], err = identifier(p); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// joinParts joins the non empty parts of a statement with spaces.
+func joinParts(parts []string) string {
+	nonEmpty := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
@@ -0,0 +1,137 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/graymeta/gmkit/postgres"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statement interface {
+	Query(rb postgres.Rebinder) (string, []interface{}, error)
+}
+
+func TestStatements(t *testing.T) {
+	items := postgres.Select("items i").Columns("i.id", "i.name").Where(postgres.ByFieldEquals("i.owner_id", "o1"))
+
+	tests := []struct {
+		name          string
+		expectedQuery string
+		expectedArgs  []interface{}
+		stmt          statement
+	}{
+		{
+			"Select",
+			`SELECT * FROM items`,
+			nil,
+			postgres.Select("items"),
+		},
+		{
+			"Select where",
+			`SELECT id, name FROM items WHERE id IN ($1, $2)`,
+			[]interface{}{"a", "b"},
+			postgres.Select("items").Columns("id", "name").Where(postgres.ByIDsIn("a", "b")),
+		},
+		{
+			"Select options",
+			`SELECT * FROM items WHERE name LIKE $1 ORDER BY name ASC, id DESC LIMIT 10 OFFSET 20`,
+			[]interface{}{"a%"},
+			postgres.Select("items").Where(postgres.Like("name", "a%")).Options(
+				postgres.Offset(20),
+				postgres.Limit(10),
+				postgres.OrderBy(postgres.OrderPair{Col: "name"}, postgres.OrderPair{Col: "id", Desc: true}),
+			),
+		},
+		{
+			"Select join",
+			`SELECT i.id, i.name, l.name FROM items i JOIN locations l ON l.id = i.location_id LEFT JOIN tags t ON t.item_id = i.id WHERE (i.owner_id = $1 AND l.kind = $2)`,
+			[]interface{}{"o1", "s3"},
+			items.Columns("l.name").
+				Join("locations l", "l.id = i.location_id").
+				LeftJoin("tags t", "t.item_id = i.id").
+				Where(postgres.ByFieldEquals("l.kind", "s3")),
+		},
+		{
+			"Select base",
+			`SELECT i.id, i.name FROM items i WHERE i.owner_id = $1`,
+			[]interface{}{"o1"},
+			items,
+		},
+		{
+			"Select raw columns",
+			`SELECT i.id, count(*) AS total FROM items i`,
+			nil,
+			postgres.Select("items AS i").Columns("i.id").RawColumns("count(*) AS total"),
+		},
+		{
+			"Update",
+			`UPDATE items SET name = $1, version = $2 WHERE (id = $3 AND (version IS NULL OR version <= $4)) RETURNING id, updated_at`,
+			[]interface{}{"a", int64(2), "i1", int64(2)},
+			postgres.Update("items").
+				Set("name", "a").
+				Set("version", int64(2)).
+				Where(postgres.ByID("i1")).
+				Where(postgres.ByFencingToken("version", 2)).
+				Returning("id", "updated_at"),
+		},
+		{
+			"Update all",
+			`UPDATE items SET name = $1`,
+			[]interface{}{"a"},
+			postgres.Update("items").Set("name", "a").Where(postgres.All()),
+		},
+		{
+			"Delete",
+			`DELETE FROM items WHERE item_id IN ($1, $2) RETURNING id`,
+			[]interface{}{"a", "b"},
+			postgres.Delete("items").Where(postgres.ByItemIDsIn("a", "b")).Returning("id"),
+		},
+		{
+			"Delete all",
+			`DELETE FROM items`,
+			nil,
+			postgres.Delete("items"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, args, err := test.stmt.Query(&rebinder{})
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedQuery, query)
+			assert.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
+
+func TestStatementErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		stmt     statement
+	}{
+		{"no table", "no table specified", postgres.Select("")},
+		{"no columns set", "no columns set", postgres.Update("items").Where(postgres.ByID("i1"))},
+		{"where", postgres.ErrKeysetMismatch.Error(), postgres.Delete("items").Where(postgres.ByKeyset(nil, "a"))},
+		{"options", `invalid identifier "name;"`, postgres.Select("items").Options(postgres.OrderBy(postgres.OrderPair{Col: "name;"}))},
+		{"select table", `invalid identifier "items; DROP TABLE items"`, postgres.Select("items; DROP TABLE items")},
+		{"select alias", `invalid identifier "items i j"`, postgres.Select("items i j")},
+		{"select columns", `invalid identifier "lower(name)"`, postgres.Select("items").Columns("id", "lower(name)")},
+		{"mixed case column", `invalid identifier "createdAt"`, postgres.Select("items").Columns("createdAt")},
+		{"join table", `invalid identifier "tags;"`, postgres.Select("items").Join("tags;", "true")},
+		{"update table", `invalid identifier "items i"`, postgres.Update("items i").Set("name", "a")},
+		{"update set", `invalid identifier "name = 'a', admin"`, postgres.Update("items").Set("name = 'a', admin", true)},
+		{"update returning", `invalid identifier "*"`, postgres.Update("items").Set("name", "a").Returning("*")},
+		{"delete table", `invalid identifier "items;"`, postgres.Delete("items;")},
+		{"delete returning", `invalid identifier "id, password"`, postgres.Delete("items").Returning("id, password")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := test.stmt.Query(&rebinder{})
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
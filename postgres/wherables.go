@@ -9,6 +9,13 @@ import (
 )
 
 // Whereable is a way of creating a functional query statement for different lookups.
+//
+// The columns of the Whereables of this package, other than Raw, must be lower
+// case letters, digits and underscores, optionally qualified by their table such
+// as `items.id`, and are used unquoted. Clause returns ErrInvalidIdentifier for
+// any other column, including mixed case names and expressions such as
+// `lower(name)`, which were previously used as is; compare those with Raw
+// instead.
 type Whereable interface {
 	Clause(rebinder Rebinder) (query string, args []interface{}, err error)
 }
@@ -26,7 +33,11 @@ type Equals struct {
 
 // Clause prints out a sql ready statement for the Rebinder to repackage.
 func (e Equals) Clause(rb Rebinder) (string, []interface{}, error) {
-	query := fmt.Sprintf("%s = %s", e.Key, rb.Rebind("?"))
+	key, err := identifier(e.Key)
+	if err != nil {
+		return "", nil, err
+	}
+	query := fmt.Sprintf("%s = %s", key, rb.Rebind("?"))
 	return query, []interface{}{e.Val}, nil
 }
 
@@ -46,7 +57,11 @@ type notEquals struct {
 
 // Clause prints out a sql ready statement for the Rebinder to repackage.
 func (ne notEquals) Clause(rb Rebinder) (string, []interface{}, error) {
-	query := fmt.Sprintf("%s != %s", ne.Key, rb.Rebind("?"))
+	key, err := identifier(ne.Key)
+	if err != nil {
+		return "", nil, err
+	}
+	query := fmt.Sprintf("%s != %s", key, rb.Rebind("?"))
 	return query, []interface{}{ne.Val}, nil
 }
 
@@ -158,7 +173,11 @@ type is struct {
 }
 
 func (i is) Clause(rb Rebinder) (string, []interface{}, error) {
-	query := fmt.Sprintf("%s IS %s", i.key, i.val)
+	key, err := identifier(i.key)
+	if err != nil {
+		return "", nil, err
+	}
+	query := fmt.Sprintf("%s IS %s", key, i.val)
 	return query, nil, nil
 }
 
@@ -178,7 +197,11 @@ type In struct {
 
 // Clause prints out a sql ready statement for the Rebinder to repackage.
 func (i In) Clause(rb Rebinder) (string, []interface{}, error) {
-	query := fmt.Sprintf("%s IN (?)", i.Key)
+	key, err := identifier(i.Key)
+	if err != nil {
+		return "", nil, err
+	}
+	query := fmt.Sprintf("%s IN (?)", key)
 	query, args, err := sqlx.In(query, i.Vals)
 	if err != nil {
 		return "", nil, err
@@ -218,13 +241,37 @@ func ByItemIDsIn(ids ...string) Whereable {
 	}
 }
 
+type raw struct {
+	query string
+	args  []interface{}
+}
+
+func (r raw) Clause(rb Rebinder) (string, []interface{}, error) {
+	return rb.Rebind(r.query), r.args, nil
+}
+
+// Raw creates a Where clause of a query with ? placeholders for the args, which
+// is used as is, so that it may compare expressions such as
+// `lower(name) = ?` or `data->>'kind' = ?`. The query must never be taken from
+// clients.
+func Raw(query string, args ...interface{}) Whereable {
+	return raw{
+		query: query,
+		args:  args,
+	}
+}
+
 type like struct {
 	key string
 	val interface{}
 }
 
 func (l like) Clause(rb Rebinder) (string, []interface{}, error) {
-	query := fmt.Sprintf("%s LIKE %s", l.key, rb.Rebind("?"))
+	key, err := identifier(l.key)
+	if err != nil {
+		return "", nil, err
+	}
+	query := fmt.Sprintf("%s LIKE %s", key, rb.Rebind("?"))
 	return query, []interface{}{l.val}, nil
 }
 
@@ -239,7 +286,9 @@ func Like(column string, val string) Whereable {
 // QueryOptions provides a means to turn a collection of Query Options into a sql safe clause.
 type QueryOptions []QueryOption
 
-// Clause prints out a sql ready statement for the Rebinder to repackage.
+// Clause prints out a sql ready statement for the Rebinder to repackage. An
+// option that could not be made, such as an OrderBy of an invalid column, is
+// left out, so call Validate first.
 func (q QueryOptions) Clause() string {
 	sort.Slice(q, func(i, j int) bool {
 		return q[i].order() < q[j].order()
@@ -255,6 +304,20 @@ func (q QueryOptions) Clause() string {
 	return strings.Join(opts, " ")
 }
 
+// Validate returns the error of the first option that could not be made, which
+// is ErrInvalidIdentifier for an OrderBy of a column that is not a valid
+// identifier, or ErrUnknownField for one made by Fields of an unknown field.
+func (q QueryOptions) Validate() error {
+	for _, v := range q {
+		if o, ok := v.(interface{ validate() error }); ok {
+			if err := o.validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // QueryOptionTypeLimit describes a limit option type
 const QueryOptionTypeLimit = "limit"
 
@@ -336,10 +399,15 @@ func Offset(o int64) QueryOption {
 
 type orderBy struct {
 	vals []string
+	err  error
 }
 
-// Clause prints out a sql ready statement for an ORDER BY.
+// Clause prints out a sql ready statement for an ORDER BY, or nothing if a
+// column is not a valid identifier.
 func (o orderBy) Clause() string {
+	if o.err != nil {
+		return ""
+	}
 	return fmt.Sprintf("ORDER BY %s", strings.Join(o.vals, ", "))
 }
 
@@ -352,17 +420,28 @@ func (o orderBy) order() int {
 }
 
 func (o orderBy) valid() bool {
-	return len(o.vals) > 0
+	return len(o.vals) > 0 && o.err == nil
+}
+
+func (o orderBy) validate() error {
+	return o.err
 }
 
-// OrderBy returns a QueryOption that applies an ORDER BY to the query.
+// OrderBy returns a QueryOption that applies an ORDER BY to the query. The
+// columns of pairs that are not Raw are checked as those of a Whereable are, and
+// the Validate of QueryOptions returns ErrInvalidIdentifier for any that is not
+// a valid identifier, whose ORDER BY is left out of Clause.
 func OrderBy(cols ...OrderPair) QueryOption {
 	var ss []string
 	for _, v := range cols {
 		if !v.valid() {
 			continue
 		}
-		ss = append(ss, v.format())
+		col, err := v.format()
+		if err != nil {
+			return orderBy{err: err}
+		}
+		ss = append(ss, col)
 	}
 	return orderBy{
 		vals: ss,
@@ -373,14 +452,50 @@ func OrderBy(cols ...OrderPair) QueryOption {
 type OrderPair struct {
 	Col  string
 	Desc bool
+	// Raw uses Col as is rather than as a checked identifier, so that it may
+	// be an expression such as `lower(name)`, a mixed case or quoted name such
+	// as `"createdAt"`, or be followed by NULLS FIRST or NULLS LAST, which is
+	// kept after the direction. Such Cols were previously used as is without
+	// Raw, and are now rejected. A raw Col must never be taken from clients.
+	Raw bool
+}
+
+// column returns the checked column of the pair, or its Col as is without any
+// NULLS ordering if it is raw.
+func (o OrderPair) column() (string, error) {
+	if !o.Raw {
+		return identifier(o.Col)
+	}
+	col, _ := o.splitNulls()
+	return col, nil
+}
+
+// splitNulls splits the NULLS FIRST or NULLS LAST off the end of a raw Col.
+func (o OrderPair) splitNulls() (col, nulls string) {
+	upper := strings.ToUpper(o.Col)
+	for _, suffix := range []string{" NULLS FIRST", " NULLS LAST"} {
+		if strings.HasSuffix(upper, suffix) {
+			i := len(o.Col) - len(suffix)
+			return strings.TrimSpace(o.Col[:i]), o.Col[i:]
+		}
+	}
+	return o.Col, ""
 }
 
-func (o OrderPair) format() string {
+func (o OrderPair) format() (string, error) {
+	col, err := o.column()
+	if err != nil {
+		return "", err
+	}
+	var nulls string
+	if o.Raw {
+		_, nulls = o.splitNulls()
+	}
 	if o.Desc {
-		return fmt.Sprintf("%s DESC", o.Col)
+		return fmt.Sprintf("%s DESC%s", col, nulls), nil
 	}
 
-	return fmt.Sprintf("%s ASC", o.Col)
+	return fmt.Sprintf("%s ASC%s", col, nulls), nil
 }
 
 func (o OrderPair) valid() bool {
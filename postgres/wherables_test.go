@@ -25,55 +25,97 @@ func TestWherables(t *testing.T) {
 		},
 		{
 			"ByFieldEquals",
-			"WHERE test = $1",
+			`WHERE test = $1`,
 			[]interface{}{"test"},
 			postgres.ByFieldEquals("test", "test"),
 		},
 		{
 			"ByItemID",
-			"WHERE item_id = $1",
+			`WHERE item_id = $1`,
 			[]interface{}{"test"},
 			postgres.ByItemID("test"),
 		},
 		{
 			"ByID",
-			"WHERE id = $1",
+			`WHERE id = $1`,
 			[]interface{}{"test"},
 			postgres.ByID("test"),
 		},
 		{
 			"ByLocationID",
-			"WHERE location_id = $1",
+			`WHERE location_id = $1`,
 			[]interface{}{"test"},
 			postgres.ByLocationID("test"),
 		},
 		{
 			"In",
-			"WHERE test IN ($1, $2, $3)",
+			`WHERE test IN ($1, $2, $3)`,
 			[]interface{}{"one", "two", "three"},
 			postgres.ByFieldIn("test", "one", "two", "three"),
 		},
 		{
 			"ByIDsIn",
-			"WHERE id IN ($1, $2, $3)",
+			`WHERE id IN ($1, $2, $3)`,
 			[]interface{}{"one", "two", "three"},
 			postgres.ByIDsIn("one", "two", "three"),
 		},
 		{
 			"ByItemIDsIn",
-			"WHERE item_id IN ($1, $2, $3)",
+			`WHERE item_id IN ($1, $2, $3)`,
 			[]interface{}{"one", "two", "three"},
 			postgres.ByItemIDsIn("one", "two", "three"),
 		},
 		{
 			"Like",
-			"WHERE test LIKE $1",
+			`WHERE test LIKE $1`,
 			[]interface{}{"test"},
 			postgres.Like("test", "test"),
 		},
+		{
+			"ByFencingToken",
+			`WHERE (fence IS NULL OR fence <= $1)`,
+			[]interface{}{int64(3)},
+			postgres.ByFencingToken("fence", 3),
+		},
+		{
+			"And with ByFencingToken",
+			`WHERE (id = $1 AND (fence IS NULL OR fence <= $2))`,
+			[]interface{}{"one", int64(3)},
+			postgres.And(
+				postgres.ByID("one"),
+				postgres.ByFencingToken("fence", 3),
+			),
+		},
+		{
+			"ByKeyset",
+			`WHERE (created_at, id) > ($1, $2)`,
+			[]interface{}{"2019-01-01", "one"},
+			postgres.ByKeyset([]postgres.OrderPair{{Col: "created_at"}, {Col: "id"}}, "2019-01-01", "one"),
+		},
+		{
+			"ByKeyset descending",
+			`WHERE (created_at, id) < ($1, $2)`,
+			[]interface{}{"2019-01-01", "one"},
+			postgres.ByKeyset([]postgres.OrderPair{{Col: "created_at", Desc: true}, {Col: "id", Desc: true}}, "2019-01-01", "one"),
+		},
+		{
+			"ByKeyset mixed directions",
+			`WHERE ((name > $1) OR (name = $2 AND id < $3))`,
+			[]interface{}{"foo", "foo", "one"},
+			postgres.ByKeyset([]postgres.OrderPair{{Col: "name"}, {Col: "id", Desc: true}}, "foo", "one"),
+		},
+		{
+			"And with ByKeyset",
+			`WHERE (location_id = $1 AND (created_at, id) > ($2, $3))`,
+			[]interface{}{"loc", "2019-01-01", "one"},
+			postgres.And(
+				postgres.ByLocationID("loc"),
+				postgres.ByKeyset([]postgres.OrderPair{{Col: "created_at"}, {Col: "id"}}, "2019-01-01", "one"),
+			),
+		},
 		{
 			"And",
-			"WHERE (id = $1 AND item_id = $2)",
+			`WHERE (id = $1 AND item_id = $2)`,
 			[]interface{}{"one", "two"},
 			postgres.And(
 				postgres.ByID("one"),
@@ -82,7 +124,7 @@ func TestWherables(t *testing.T) {
 		},
 		{
 			"Or",
-			"WHERE (id = $1 OR item_id = $2)",
+			`WHERE (id = $1 OR item_id = $2)`,
 			[]interface{}{"one", "two"},
 			postgres.Or(
 				postgres.ByID("one"),
@@ -91,7 +133,7 @@ func TestWherables(t *testing.T) {
 		},
 		{
 			"And and Or",
-			"WHERE (id = $1 AND (item_id = $2 OR item_id = $3))",
+			`WHERE (id = $1 AND (item_id = $2 OR item_id = $3))`,
 			[]interface{}{"one", "two", "three"},
 			postgres.And(
 				postgres.ByID("one"),
@@ -115,6 +157,14 @@ func TestWherables(t *testing.T) {
 	}
 }
 
+func TestByKeysetMismatch(t *testing.T) {
+	_, _, err := postgres.ToWhereClause(&rebinder{}, postgres.ByKeyset([]postgres.OrderPair{{Col: "created_at"}, {Col: "id"}}, "2019-01-01"))
+	assert.Equal(t, postgres.ErrKeysetMismatch, err)
+
+	_, _, err = postgres.ToWhereClause(&rebinder{}, postgres.ByKeyset(nil))
+	assert.Equal(t, postgres.ErrKeysetMismatch, err)
+}
+
 func TestQueryOption(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -133,7 +183,7 @@ func TestQueryOption(t *testing.T) {
 		},
 		{
 			"OrderBy",
-			"ORDER BY one ASC, two DESC",
+			`ORDER BY one ASC, two DESC`,
 			postgres.OrderBy(
 				postgres.OrderPair{Col: "one", Desc: false},
 				postgres.OrderPair{Col: "two", Desc: true},
@@ -151,13 +201,14 @@ func TestQueryOption(t *testing.T) {
 
 func TestQueryOptions(t *testing.T) {
 	expectedQuery := "ORDER BY test ASC LIMIT 123 OFFSET 456"
-	actualQuery := postgres.QueryOptions{
+	opts := postgres.QueryOptions{
 		postgres.Offset(456),
 		postgres.Limit(123),
 		postgres.OrderBy(postgres.OrderPair{Col: "test", Desc: false}),
-	}.Clause()
+	}
 
-	assert.Equal(t, expectedQuery, actualQuery)
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, expectedQuery, opts.Clause())
 }
 
 type rebinder struct{}
@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many calls to AllowN are made between removals of expired
+// keys from a MemoryLimiter.
+const sweepEvery = 1024
+
+// MemoryLimiter is a Limiter that enforces a Limit within a single process,
+// with the same semantics as a RedisLimiter.
+type MemoryLimiter struct {
+	limit Limit
+	now   func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*memoryState
+	calls  int
+}
+
+type memoryState struct {
+	// count and start are the count and start of the current window. prev is
+	// the count of the previous window, used by sliding windows.
+	count int
+	prev  int
+	start time.Time
+	// tat is the theoretical arrival time used by GCRA.
+	tat time.Time
+	// expires is when the state can be discarded.
+	expires time.Time
+}
+
+var _ Limiter = (*MemoryLimiter)(nil)
+
+// NewMemoryLimiter initializes a new MemoryLimiter.
+func NewMemoryLimiter(limit Limit) *MemoryLimiter {
+	return &MemoryLimiter{
+		limit:  limit,
+		now:    time.Now,
+		states: make(map[string]*memoryState),
+	}
+}
+
+// Allow reports whether a single operation identified by key is allowed now,
+// consuming the allowance if it is.
+func (l *MemoryLimiter) Allow(key string) (Result, error) {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n operations identified by key are allowed now,
+// consuming the allowance if they are.
+func (l *MemoryLimiter) AllowN(key string, n int) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	s, ok := l.states[key]
+	if !ok || !now.Before(s.expires) {
+		s = &memoryState{}
+		l.states[key] = s
+	}
+
+	switch l.limit.alg {
+	case slidingWindow:
+		return l.slidingWindow(s, now, n), nil
+	case gcra:
+		return l.gcra(s, now, n), nil
+	default:
+		return l.fixedWindow(s, now, n), nil
+	}
+}
+
+func (l *MemoryLimiter) fixedWindow(s *memoryState, now time.Time, n int) Result {
+	window, limit := l.limit.period, l.limit.limit
+	if s.start.IsZero() {
+		s.start = now
+		s.expires = now.Add(window)
+	}
+
+	res := Result{Limit: limit, ResetAfter: s.expires.Sub(now)}
+	if s.count+n > limit {
+		res.Remaining = limit - s.count
+		res.RetryAfter = res.ResetAfter
+		return res
+	}
+	s.count += n
+	res.Allowed = true
+	res.Remaining = limit - s.count
+	return res
+}
+
+func (l *MemoryLimiter) slidingWindow(s *memoryState, now time.Time, n int) Result {
+	window, limit := l.limit.period, l.limit.limit
+
+	start := now.Truncate(window)
+	switch {
+	case s.start.Equal(start):
+	case s.start.Equal(start.Add(-window)):
+		s.prev, s.count = s.count, 0
+	default:
+		s.prev, s.count = 0, 0
+	}
+	s.start = start
+	s.expires = start.Add(2 * window)
+
+	elapsed := now.Sub(start)
+	estimate := slidingEstimate(s.prev, s.count, elapsed, window)
+
+	res := Result{Limit: limit, ResetAfter: window - elapsed}
+	if estimate+n > limit {
+		res.Remaining = max(limit-estimate, 0)
+		res.RetryAfter = slidingRetryAfter(s.prev, s.count, n, limit, elapsed, window)
+		return res
+	}
+	s.count += n
+	res.Allowed = true
+	res.Remaining = max(limit-estimate-n, 0)
+	return res
+}
+
+// slidingEstimate estimates the operations in the sliding window by weighting
+// the previous window's count by how much of it the sliding window overlaps.
+func slidingEstimate(prev, count int, elapsed, window time.Duration) int {
+	return int(float64(prev)*float64(window-elapsed)/float64(window)) + count
+}
+
+// slidingRetryAfter returns the time until the weight of the previous window
+// has decayed enough for n operations to be allowed, or until the next window
+// if the current window alone is over the limit.
+func slidingRetryAfter(prev, count, n, limit int, elapsed, window time.Duration) time.Duration {
+	if count+n > limit || prev == 0 {
+		return window - elapsed
+	}
+	// solve prev * (window - elapsed - x) / window + count + n <= limit for x.
+	x := window - elapsed - time.Duration(float64(limit-count-n)*float64(window)/float64(prev))
+	if x < 0 {
+		return 0
+	}
+	return x
+}
+
+func (l *MemoryLimiter) gcra(s *memoryState, now time.Time, n int) Result {
+	interval, burst := l.limit.period, l.limit.limit
+	tolerance := interval * time.Duration(burst)
+
+	tat := s.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval * time.Duration(n))
+	allowAt := newTAT.Add(-tolerance)
+
+	res := Result{Limit: burst}
+	if now.Before(allowAt) {
+		res.Remaining = gcraRemaining(tolerance-tat.Sub(now), interval)
+		res.ResetAfter = tat.Sub(now)
+		res.RetryAfter = allowAt.Sub(now)
+		return res
+	}
+
+	s.tat = newTAT
+	s.expires = newTAT
+	res.Allowed = true
+	res.Remaining = gcraRemaining(tolerance-newTAT.Sub(now), interval)
+	res.ResetAfter = newTAT.Sub(now)
+	return res
+}
+
+func gcraRemaining(available, interval time.Duration) int {
+	if available < 0 {
+		return 0
+	}
+	return int(available / interval)
+}
+
+// sweep removes expired states every sweepEvery calls.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	l.calls++
+	if l.calls < sweepEvery {
+		return
+	}
+	l.calls = 0
+	for key, s := range l.states {
+		if !now.Before(s.expires) {
+			delete(l.states, key)
+		}
+	}
+}
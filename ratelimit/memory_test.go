@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	testLimiter(t, func(t *testing.T, limit Limit) Limiter {
+		return NewMemoryLimiter(limit)
+	})
+
+	t.Run("sliding window weights the previous window", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		l := NewMemoryLimiter(SlidingWindow(10, time.Minute))
+		l.now = func() time.Time { return now }
+
+		for i := 0; i < 10; i++ {
+			res, err := l.Allow("key")
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+		}
+
+		// a quarter into the next window, three quarters of the previous
+		// window's count is estimated to be in the sliding window.
+		now = now.Add(75 * time.Second)
+		res, err := l.AllowN("key", 4)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 3, res.Remaining)
+		assert.Equal(t, 45*time.Second, res.ResetAfter)
+		assert.Equal(t, 9*time.Second, res.RetryAfter)
+
+		res, err = l.AllowN("key", 3)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+
+		res, err = l.Allow("key")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 9*time.Second, res.RetryAfter)
+
+		now = now.Add(9 * time.Second)
+		res, err = l.Allow("key")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("expired keys are swept", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		l := NewMemoryLimiter(FixedWindow(1, time.Second))
+		l.now = func() time.Time { return now }
+
+		_, err := l.Allow("expired")
+		require.NoError(t, err)
+		now = now.Add(time.Minute)
+		for i := 0; i < sweepEvery; i++ {
+			_, err := l.Allow("key")
+			require.NoError(t, err)
+		}
+		assert.NotContains(t, l.states, "expired")
+		assert.Contains(t, l.states, "key")
+	})
+}
+
+// testLimiter runs the tests every Limiter is expected to pass.
+func testLimiter(t *testing.T, newLimiter func(t *testing.T, limit Limit) Limiter) {
+	t.Run("fixed window", func(t *testing.T) {
+		const window = 200 * time.Millisecond
+		l := newLimiter(t, FixedWindow(3, window))
+
+		for i := 2; i >= 0; i-- {
+			res, err := l.Allow("key")
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			assert.Equal(t, 3, res.Limit)
+			assert.Equal(t, i, res.Remaining)
+			assert.True(t, res.ResetAfter > 0 && res.ResetAfter <= window, "reset after %s", res.ResetAfter)
+		}
+
+		res, err := l.Allow("key")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+		assert.True(t, res.RetryAfter > 0 && res.RetryAfter <= window, "retry after %s", res.RetryAfter)
+
+		res, err = l.Allow("other")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "keys are limited independently")
+
+		time.Sleep(window + 50*time.Millisecond)
+		res, err = l.AllowN("key", 3)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+	})
+
+	t.Run("sliding window", func(t *testing.T) {
+		const window = 200 * time.Millisecond
+		l := newLimiter(t, SlidingWindow(4, window))
+
+		// start at the beginning of a window, so that the requests below are
+		// made in the same window.
+		now := time.Now()
+		time.Sleep(now.Truncate(window).Add(window).Sub(now))
+
+		res, err := l.AllowN("key", 5)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed, "more than the limit is never allowed")
+
+		res, err = l.AllowN("key", 4)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+
+		res, err = l.Allow("key")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.True(t, res.RetryAfter > 0 && res.RetryAfter <= window, "retry after %s", res.RetryAfter)
+
+		time.Sleep(2*window + 50*time.Millisecond)
+		res, err = l.AllowN("key", 4)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("GCRA", func(t *testing.T) {
+		const interval = 100 * time.Millisecond
+		l := newLimiter(t, GCRA(10, time.Second, 3))
+
+		for i := 2; i >= 0; i-- {
+			res, err := l.Allow("key")
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			assert.Equal(t, 3, res.Limit)
+			assert.Equal(t, i, res.Remaining)
+		}
+
+		res, err := l.Allow("key")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.InDelta(t, interval, res.RetryAfter, float64(20*time.Millisecond))
+		assert.InDelta(t, 3*interval, res.ResetAfter, float64(20*time.Millisecond))
+
+		time.Sleep(res.RetryAfter + 10*time.Millisecond)
+		res, err = l.Allow("key")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+	})
+}
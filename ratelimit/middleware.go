@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/graymeta/gmkit/api"
+	gmerrors "github.com/graymeta/gmkit/errors"
+)
+
+// KeyFn returns the key a request is limited by. Requests with an empty key
+// are not limited.
+type KeyFn func(req *http.Request) string
+
+// ByIP limits requests by the IP address of the client, as seen by the server.
+func ByIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// ByHeader limits requests by the value of a header, such as one identifying
+// the tenant.
+func ByHeader(name string) KeyFn {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// Middleware is middleware that limits requests with l by the key returned
+// by keyFn. It sets the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers on every limited response, and responds to requests
+// over the limit with a 429 and a Retry-After header through r. If l returns
+// an error the request is allowed, so that an outage of the limiter does not
+// take down the API; use Fallback to keep limiting during one.
+func Middleware(l Limiter, keyFn KeyFn, r *api.Responder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			key := keyFn(req)
+			if key == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			res, err := l.Allow(key)
+			if err != nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", seconds(res.ResetAfter))
+			if !res.Allowed {
+				h.Set("Retry-After", seconds(res.RetryAfter))
+				r.Err(w, req, &gmerrors.HTTPErr{Msg: "rate limit exceeded", Code: http.StatusTooManyRequests})
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// seconds formats d as whole seconds, rounded up so that clients do not retry
+// too early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/api"
+	"github.com/graymeta/gmkit/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		limiter   Limiter
+		tenant    string
+		status    int
+		headers   map[string]string
+		noHeaders bool
+	}{
+		{
+			name:    "allowed",
+			limiter: NewMemoryLimiter(FixedWindow(2, time.Minute)),
+			tenant:  "t1",
+			status:  http.StatusOK,
+			headers: map[string]string{
+				"RateLimit-Limit":     "2",
+				"RateLimit-Remaining": "1",
+				"RateLimit-Reset":     "60",
+			},
+		},
+		{
+			name:    "limited",
+			limiter: NewMemoryLimiter(FixedWindow(0, time.Minute)),
+			tenant:  "t1",
+			status:  http.StatusTooManyRequests,
+			headers: map[string]string{
+				"RateLimit-Limit":     "0",
+				"RateLimit-Remaining": "0",
+				"Retry-After":         "60",
+			},
+		},
+		{
+			name:      "no key",
+			limiter:   NewMemoryLimiter(FixedWindow(0, time.Minute)),
+			status:    http.StatusOK,
+			noHeaders: true,
+		},
+		{
+			name:      "limiter error",
+			limiter:   errLimiter{},
+			tenant:    "t1",
+			status:    http.StatusOK,
+			noHeaders: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := Middleware(test.limiter, ByHeader("X-Tenant"), api.NewResponder(logger.Silence(), ""))(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-Tenant", test.tenant)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			for k, v := range test.headers {
+				assert.Equal(t, v, rec.Header().Get(k), k)
+			}
+			if test.noHeaders {
+				assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+			}
+		})
+	}
+}
+
+func TestByIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "10.0.0.1", ByIP(req))
+}
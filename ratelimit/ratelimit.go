@@ -0,0 +1,115 @@
+// Package ratelimit limits the rate of operations identified by a key, such as
+// the requests of a tenant. Limits are enforced across replicas with a
+// RedisLimiter, or within a single process with a MemoryLimiter.
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Result is the outcome of asking a Limiter for an allowance.
+type Result struct {
+	// Allowed is whether the operation is allowed.
+	Allowed bool
+	// Limit is the number of operations allowed in a window, or the burst of a
+	// GCRA limit.
+	Limit int
+	// Remaining is the number of operations that would be allowed immediately
+	// after this one.
+	Remaining int
+	// ResetAfter is the time until the limit has fully reset.
+	ResetAfter time.Duration
+	// RetryAfter is the time until the operation would be allowed, when it was
+	// not.
+	RetryAfter time.Duration
+}
+
+// Limiter limits the rate of operations identified by a key.
+type Limiter interface {
+	// Allow reports whether a single operation identified by key is allowed
+	// now, consuming the allowance if it is.
+	Allow(key string) (Result, error)
+	// AllowN reports whether n operations identified by key are allowed now,
+	// consuming the allowance if they are.
+	AllowN(key string, n int) (Result, error)
+}
+
+type algorithm int
+
+const (
+	fixedWindow algorithm = iota
+	slidingWindow
+	gcra
+)
+
+// Limit describes a rate limit and the algorithm enforcing it.
+type Limit struct {
+	alg    algorithm
+	limit  int
+	period time.Duration
+}
+
+// FixedWindow allows limit operations in each window, which starts with the
+// first operation after the previous window ends. It is the cheapest to
+// enforce, but allows up to twice the limit across the edge of two windows.
+// FixedWindow panics if limit is negative or window is not positive.
+func FixedWindow(limit int, window time.Duration) Limit {
+	validateWindow("FixedWindow", limit, window)
+	return Limit{alg: fixedWindow, limit: limit, period: window}
+}
+
+// SlidingWindow allows limit operations in any window, estimating the
+// operations in the window from the counts of the current and previous fixed
+// windows. SlidingWindow panics if limit is negative or window is not
+// positive.
+func SlidingWindow(limit int, window time.Duration) Limit {
+	validateWindow("SlidingWindow", limit, window)
+	return Limit{alg: slidingWindow, limit: limit, period: window}
+}
+
+// GCRA allows operations at a steady rate of rate per period, with bursts of up
+// to burst operations, using the generic cell rate algorithm. It behaves as a
+// token bucket holding burst tokens that refills at the rate. GCRA panics if
+// rate, period or burst is not positive, or if the rate is more than one
+// operation per nanosecond.
+func GCRA(rate int, period time.Duration, burst int) Limit {
+	if rate <= 0 || period <= 0 || burst <= 0 {
+		panic(fmt.Sprintf("ratelimit: GCRA rate, period and burst must be positive, got %d, %s and %d", rate, period, burst))
+	}
+	interval := period / time.Duration(rate)
+	if interval <= 0 {
+		panic(fmt.Sprintf("ratelimit: GCRA rate of %d per %s is more than one per nanosecond", rate, period))
+	}
+	return Limit{alg: gcra, limit: burst, period: interval}
+}
+
+func validateWindow(alg string, limit int, window time.Duration) {
+	if limit < 0 || window <= 0 {
+		panic(fmt.Sprintf("ratelimit: %s limit must not be negative and window must be positive, got %d and %s", alg, limit, window))
+	}
+}
+
+// Fallback returns a Limiter that asks primary for an allowance, falling back
+// to secondary if primary returns an error. A typical use is a RedisLimiter
+// falling back to a MemoryLimiter with the same Limit, so that rate limiting
+// continues per process while Redis is unavailable.
+func Fallback(primary, secondary Limiter) Limiter {
+	return fallback{primary: primary, secondary: secondary}
+}
+
+type fallback struct {
+	primary, secondary Limiter
+}
+
+func (f fallback) Allow(key string) (Result, error) {
+	return f.AllowN(key, 1)
+}
+
+func (f fallback) AllowN(key string, n int) (Result, error) {
+	res, err := f.primary.AllowN(key, n)
+	if err != nil {
+		return f.secondary.AllowN(key, n)
+	}
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errLimiter struct{}
+
+func (errLimiter) Allow(key string) (Result, error) {
+	return Result{}, errors.New("unavailable")
+}
+
+func (errLimiter) AllowN(key string, n int) (Result, error) {
+	return Result{}, errors.New("unavailable")
+}
+
+func TestFallback(t *testing.T) {
+	l := Fallback(errLimiter{}, NewMemoryLimiter(FixedWindow(1, time.Minute)))
+
+	res, err := l.Allow("key")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = l.Allow("key")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+}
+
+func TestInvalidLimits(t *testing.T) {
+	tests := []struct {
+		description string
+		limit       func() Limit
+		msg         string
+	}{
+		{"negative fixed limit", func() Limit { return FixedWindow(-1, time.Minute) }, "ratelimit: FixedWindow limit must not be negative and window must be positive, got -1 and 1m0s"},
+		{"zero fixed window", func() Limit { return FixedWindow(1, 0) }, "ratelimit: FixedWindow limit must not be negative and window must be positive, got 1 and 0s"},
+		{"negative sliding window", func() Limit { return SlidingWindow(1, -time.Second) }, "ratelimit: SlidingWindow limit must not be negative and window must be positive, got 1 and -1s"},
+		{"zero gcra rate", func() Limit { return GCRA(0, time.Second, 1) }, "ratelimit: GCRA rate, period and burst must be positive, got 0, 1s and 1"},
+		{"zero gcra period", func() Limit { return GCRA(1, 0, 1) }, "ratelimit: GCRA rate, period and burst must be positive, got 1, 0s and 1"},
+		{"zero gcra burst", func() Limit { return GCRA(1, time.Second, 0) }, "ratelimit: GCRA rate, period and burst must be positive, got 1, 1s and 0"},
+		{"gcra rate too high", func() Limit { return GCRA(2, time.Nanosecond, 1) }, "ratelimit: GCRA rate of 2 per 1ns is more than one per nanosecond"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.PanicsWithValue(t, tt.msg, func() { tt.limit() })
+		})
+	}
+
+	assert.NotPanics(t, func() { FixedWindow(0, time.Minute) })
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/graymeta/gmkit/internal/redislua"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// RedisLimiter is a Limiter that enforces a Limit across every process sharing
+// the Redis pool. Each check is a single Lua script, so concurrent checks for a
+// key are atomic. Time is read from the Redis server, so the clocks of the
+// processes do not need to agree.
+type RedisLimiter struct {
+	pool   *redis.Pool
+	prefix string
+	limit  Limit
+}
+
+var _ Limiter = (*RedisLimiter)(nil)
+
+// NewRedisLimiter initializes a new RedisLimiter. The state of each key is
+// stored under prefix+key, so limiters with different Limits should use
+// different prefixes.
+func NewRedisLimiter(pool *redis.Pool, prefix string, limit Limit) *RedisLimiter {
+	return &RedisLimiter{
+		pool:   pool,
+		prefix: prefix,
+		limit:  limit,
+	}
+}
+
+// Allow reports whether a single operation identified by key is allowed now,
+// consuming the allowance if it is.
+func (l *RedisLimiter) Allow(key string) (Result, error) {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n operations identified by key are allowed now,
+// consuming the allowance if they are.
+func (l *RedisLimiter) AllowN(key string, n int) (Result, error) {
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	var (
+		script *redis.Script
+		period = l.limit.period / time.Microsecond
+	)
+	switch l.limit.alg {
+	case slidingWindow:
+		script = slidingWindowScript
+	case gcra:
+		script = gcraScript
+	default:
+		script = fixedWindowScript
+	}
+
+	vals, err := redis.Int64s(script.Do(conn, l.prefix+key, l.limit.limit, int64(period), n))
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    vals[0] == 1,
+		Limit:      l.limit.limit,
+		Remaining:  int(vals[1]),
+		ResetAfter: time.Duration(vals[2]) * time.Microsecond,
+		RetryAfter: time.Duration(vals[3]) * time.Microsecond,
+	}, nil
+}
+
+// Each script takes the key of the state as KEYS[1], and the limit, the period
+// in microseconds and n as ARGV. Each returns whether the operations were
+// allowed, the remaining count, and the microseconds until the limit resets and
+// until the operations would be allowed.
+
+var fixedWindowScript = redis.NewScript(1, `
+	local limit, period, n = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+	local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+	if count + n > limit then
+		local reset = redis.call('PTTL', KEYS[1]) * 1000
+		if reset < 0 then
+			reset = period
+		end
+		return {0, limit - count, reset, reset}
+	end
+
+	count = redis.call('INCRBY', KEYS[1], n)
+	local reset = redis.call('PTTL', KEYS[1]) * 1000
+	if reset < 0 then
+		reset = period
+		redis.call('PEXPIRE', KEYS[1], math.ceil(period / 1000))
+	end
+	return {1, limit - count, reset, 0}`)
+
+var slidingWindowScript = redis.NewScript(1, redislua.Time+`
+	local limit, period, n = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+	local now = currentMicros()
+	local start = now - (now % period)
+	local state = redis.call('HMGET', KEYS[1], 'start', 'count', 'prev')
+	local count, prev = tonumber(state[2]) or 0, tonumber(state[3]) or 0
+	if tonumber(state[1]) == start - period then
+		prev, count = count, 0
+	elseif tonumber(state[1]) ~= start then
+		prev, count = 0, 0
+	end
+
+	local elapsed = now - start
+	local reset = period - elapsed
+	local estimate = math.floor(prev * (period - elapsed) / period) + count
+	if estimate + n > limit then
+		local retry = reset
+		if count + n <= limit and prev > 0 then
+			-- wait for the weight of the previous window to decay enough.
+			retry = math.max(period - elapsed - math.floor((limit - count - n) * period / prev), 0)
+		end
+		return {0, math.max(limit - estimate, 0), reset, retry}
+	end
+
+	redis.call('HMSET', KEYS[1], 'start', string.format('%d', start), 'count', count + n, 'prev', prev)
+	redis.call('PEXPIRE', KEYS[1], math.ceil((reset + period) / 1000))
+	return {1, math.max(limit - estimate - n, 0), reset, 0}`)
+
+// gcraScript is given the burst as the limit and the emission interval as the
+// period.
+var gcraScript = redis.NewScript(1, redislua.Time+`
+	local limit, period, n = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+	local now = currentMicros()
+	local tolerance = period * limit
+	local tat = math.max(tonumber(redis.call('GET', KEYS[1]) or '0'), now)
+	local newTAT = tat + period * n
+	local allowAt = newTAT - tolerance
+	if now < allowAt then
+		return {0, math.max(math.floor((tolerance - (tat - now)) / period), 0), tat - now, allowAt - now}
+	end
+
+	redis.call('SET', KEYS[1], string.format('%d', newTAT), 'PX', math.max(math.ceil((newTAT - now) / 1000), 1))
+	return {1, math.max(math.floor((tolerance - (newTAT - now)) / period), 0), newTAT - now, 0}`)
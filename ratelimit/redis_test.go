@@ -0,0 +1,15 @@
+// +build int
+
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/graymeta/gmkit/testhelpers/redis"
+)
+
+func TestRedisLimiter(t *testing.T) {
+	testLimiter(t, func(t *testing.T, limit Limit) Limiter {
+		return NewRedisLimiter(redis.Setup(t), "ratelimit:", limit)
+	})
+}
@@ -0,0 +1,154 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/schedule"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2020, time.January, 15, 10, 30, 15, 500, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected []time.Time
+	}{
+		{
+			"* * * * *",
+			[]time.Time{
+				time.Date(2020, time.January, 15, 10, 31, 0, 0, time.UTC),
+				time.Date(2020, time.January, 15, 10, 32, 0, 0, time.UTC),
+			},
+		},
+		{
+			"*/20 * * * * *",
+			[]time.Time{
+				time.Date(2020, time.January, 15, 10, 30, 20, 0, time.UTC),
+				time.Date(2020, time.January, 15, 10, 30, 40, 0, time.UTC),
+				time.Date(2020, time.January, 15, 10, 31, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 2 * * *",
+			[]time.Time{
+				time.Date(2020, time.January, 16, 2, 0, 0, 0, time.UTC),
+				time.Date(2020, time.January, 17, 2, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"15,45 9-11 * * mon-fri",
+			[]time.Time{
+				time.Date(2020, time.January, 15, 10, 45, 0, 0, time.UTC),
+				time.Date(2020, time.January, 15, 11, 15, 0, 0, time.UTC),
+				time.Date(2020, time.January, 15, 11, 45, 0, 0, time.UTC),
+				time.Date(2020, time.January, 16, 9, 15, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 0 * * 7",
+			[]time.Time{
+				time.Date(2020, time.January, 19, 0, 0, 0, 0, time.UTC),
+				time.Date(2020, time.January, 26, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 0 1 * 1",
+			[]time.Time{
+				time.Date(2020, time.January, 20, 0, 0, 0, 0, time.UTC),
+				time.Date(2020, time.January, 27, 0, 0, 0, 0, time.UTC),
+				time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2020, time.February, 3, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 0 29 feb *",
+			[]time.Time{
+				time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"0 12 31 * *",
+			[]time.Time{
+				time.Date(2020, time.January, 31, 12, 0, 0, 0, time.UTC),
+				time.Date(2020, time.March, 31, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"@monthly",
+			[]time.Time{
+				time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"@yearly",
+			[]time.Time{
+				time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			"@every 15m",
+			[]time.Time{
+				time.Date(2020, time.January, 15, 10, 45, 0, 0, time.UTC),
+				time.Date(2020, time.January, 15, 11, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			sched, err := schedule.Parse(test.expr)
+			require.NoError(t, err)
+
+			next := from
+			for _, expected := range test.expected {
+				next = sched.Next(next)
+				assert.Equal(t, expected, next)
+			}
+		})
+	}
+
+	t.Run("location", func(t *testing.T) {
+		loc := time.FixedZone("UTC-5", -5*60*60)
+		sched, err := schedule.Parse("0 2 * * *")
+		require.NoError(t, err)
+
+		next := sched.Next(from.In(loc))
+		assert.Equal(t, time.Date(2020, time.January, 16, 2, 0, 0, 0, loc), next)
+	})
+
+	t.Run("never matches", func(t *testing.T) {
+		sched, err := schedule.Parse("0 0 30 feb *")
+		require.NoError(t, err)
+		assert.True(t, sched.Next(from).IsZero())
+	})
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every",
+		"@every -1s",
+		"@sometimes",
+	}
+	for _, expr := range invalid {
+		t.Run("invalid "+expr, func(t *testing.T) {
+			_, err := schedule.Parse(expr)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,299 @@
+// Package schedule runs jobs on cron schedules across replicas of a service,
+// using a lock.Locker so that each scheduled run of a job is executed by only
+// one replica.
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/graymeta/gmkit/backoff"
+	"github.com/graymeta/gmkit/lock"
+	"github.com/graymeta/gmkit/logger"
+	"github.com/graymeta/gmkit/redmetrics"
+)
+
+// ErrDuplicateJob is returned when registering a job with a name that is
+// already registered.
+var ErrDuplicateJob = errors.New("job already registered")
+
+// JobFn is the func run by a job. The context is cancelled once the grace
+// period has elapsed after the scheduler is shut down.
+type JobFn func(ctx context.Context) error
+
+// MissedRunPolicy determines what happens to runs that were due while a
+// previous run of the job was still in progress.
+type MissedRunPolicy int
+
+const (
+	// SkipMissed skips the missed runs and waits for the next scheduled run.
+	SkipMissed MissedRunPolicy = iota
+	// RunMissedOnce runs the job once as soon as the previous run completes,
+	// however many runs were missed.
+	RunMissedOnce
+)
+
+// OptFn is a functional option for configuring a Scheduler.
+type OptFn func(s *Scheduler)
+
+// WithLogger sets the logger of the Scheduler.
+func WithLogger(l *logger.L) OptFn {
+	return func(s *Scheduler) {
+		s.log = l
+	}
+}
+
+// WithLocation sets the location cron expressions are evaluated in. Defaults to
+// UTC.
+func WithLocation(loc *time.Location) OptFn {
+	return func(s *Scheduler) {
+		s.loc = loc
+	}
+}
+
+// WithGracePeriod sets how long running jobs are given to complete once the
+// scheduler is shut down before their context is cancelled. Defaults to 30
+// seconds.
+func WithGracePeriod(d time.Duration) OptFn {
+	return func(s *Scheduler) {
+		s.grace = d
+	}
+}
+
+// JobOptFn is a functional option for configuring a job.
+type JobOptFn func(j *job)
+
+// Backoff sets the backoff used to retry a failed run of the job. Defaults to
+// a single attempt.
+func Backoff(b backoff.Backoffer) JobOptFn {
+	return func(j *job) {
+		j.backoff = b
+	}
+}
+
+// Jitter delays each run of the job by a random duration up to max, to spread
+// the load of jobs scheduled at the same time.
+func Jitter(max time.Duration) JobOptFn {
+	return func(j *job) {
+		j.jitter = max
+	}
+}
+
+// MissedRuns sets the policy for runs missed while a previous run of the job
+// was in progress. Defaults to SkipMissed.
+func MissedRuns(p MissedRunPolicy) JobOptFn {
+	return func(j *job) {
+		j.missed = p
+	}
+}
+
+// LockTTL sets how long the lock for each run is held, which must be longer
+// than the clock skew between replicas. The lock is not released after the run
+// so that a replica arriving late does not run it again, but once the following
+// run is due. Defaults to the time until the following run, up to an hour.
+func LockTTL(d time.Duration) JobOptFn {
+	return func(j *job) {
+		j.lockTTL = d
+	}
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       JobFn
+	backoff  backoff.Backoffer
+	jitter   time.Duration
+	missed   MissedRunPolicy
+	lockTTL  time.Duration
+}
+
+// Scheduler runs registered jobs on their schedules. Replicas of a service
+// sharing the same Locker run each scheduled run of a job once between them.
+type Scheduler struct {
+	locker  lock.Locker
+	id      string
+	log     *logger.L
+	loc     *time.Location
+	grace   time.Duration
+	metrics redmetrics.Client
+
+	mu   sync.Mutex
+	jobs []*job
+}
+
+// New initializes a Scheduler for the replica with the given unique id.
+func New(l lock.Locker, uniqueID string, opts ...OptFn) *Scheduler {
+	s := &Scheduler{
+		locker:  l,
+		id:      uniqueID,
+		log:     logger.Silence(),
+		loc:     time.UTC,
+		grace:   30 * time.Second,
+		metrics: redmetrics.NewClient("schedule"),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Register adds a job that runs fn on the schedule of the cron expression. See
+// Parse for the syntax of the expression. Jobs must be registered before
+// calling Run.
+func (s *Scheduler) Register(name, expr string, fn JobFn, opts ...JobOptFn) error {
+	sched, err := Parse(expr)
+	if err != nil {
+		return err
+	}
+
+	j := &job{
+		name:     name,
+		schedule: sched,
+		fn:       fn,
+		backoff:  backoff.NoopBackoff{},
+		missed:   SkipMissed,
+	}
+	for _, o := range opts {
+		o(j)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.jobs {
+		if existing.name == name {
+			return ErrDuplicateJob
+		}
+	}
+	s.jobs = append(s.jobs, j)
+	return nil
+}
+
+// Run runs the registered jobs until the context is cancelled. Once it is, no
+// more runs are started, and Run waits for the running jobs to complete, or for
+// the grace period to elapse and the jobs to return after their context is
+// cancelled. Run returns nil once shut down.
+func (s *Scheduler) Run(ctx context.Context) error {
+	jobCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.mu.Lock()
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.loop(ctx, jobCtx, j)
+		}(j)
+	}
+
+	<-ctx.Done()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(s.grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		s.log.Warn("schedule_shutdown", "msg", "grace period elapsed, cancelling running jobs")
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+// loop schedules the runs of a job until ctx is cancelled. Runs are executed
+// with jobCtx, which outlives ctx by the grace period.
+func (s *Scheduler) loop(ctx, jobCtx context.Context, j *job) {
+	rander := rand.New(rand.NewSource(time.Now().UnixNano()))
+	next := j.schedule.Next(time.Now().In(s.loc))
+	var held string
+	for !next.IsZero() {
+		delay := time.Until(next)
+		if j.jitter > 0 {
+			delay += time.Duration(rander.Int63n(int64(j.jitter)))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.unlock(j, held)
+		held = s.run(jobCtx, j, next)
+
+		following := j.schedule.Next(next)
+		now := time.Now().In(s.loc)
+		if !following.IsZero() && !following.After(now) {
+			s.log.Warn("schedule_missed", "job", j.name, "scheduled", following)
+			switch j.missed {
+			case SkipMissed:
+				following = j.schedule.Next(now)
+			case RunMissedOnce:
+				// run only the most recent of the missed runs.
+				for n := j.schedule.Next(following); !n.IsZero() && !n.After(now); n = j.schedule.Next(n) {
+					following = n
+				}
+			}
+		}
+		next = following
+	}
+}
+
+// run executes the scheduled run of the job at tick, if this replica acquires
+// the lock for it, and returns the name of the lock it holds, if any.
+func (s *Scheduler) run(ctx context.Context, j *job, tick time.Time) string {
+	ttl := j.lockTTL
+	if ttl == 0 {
+		ttl = time.Hour
+		if following := j.schedule.Next(tick); !following.IsZero() && following.Sub(tick) < ttl {
+			ttl = following.Sub(tick)
+		}
+	}
+
+	name := fmt.Sprintf("schedule:%s:%d", j.name, tick.UnixNano())
+	acquired, err := s.locker.Lock(name, s.id, ttl)
+	if err != nil {
+		s.metrics.IncErrs(j.name + "_lock")
+		s.log.Err("schedule_lock", "job", j.name, "scheduled", tick, "error", err)
+		return ""
+	}
+	if !acquired {
+		return ""
+	}
+
+	record := s.metrics.REDMetrics(j.name)
+	err = record(j.backoff.BackoffCtx(ctx, j.fn))
+	if err != nil {
+		s.log.Err("schedule_run", "job", j.name, "scheduled", tick, "error", err)
+		return name
+	}
+	s.log.Debug("schedule_run", "job", j.name, "scheduled", tick)
+	return name
+}
+
+// unlock releases the lock of a previous run of the job once the following run
+// is due, so that the locker does not keep a lock for every run. A lock that
+// already expired is left to the locker.
+func (s *Scheduler) unlock(j *job, name string) {
+	if name == "" {
+		return
+	}
+	if err := s.locker.Unlock(name, s.id); err != nil && err != lock.ErrUnlock {
+		s.metrics.IncErrs(j.name + "_unlock")
+		s.log.Err("schedule_unlock", "job", j.name, "lock", name, "error", err)
+	}
+}
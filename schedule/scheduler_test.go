@@ -0,0 +1,210 @@
+package schedule_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/graymeta/gmkit/backoff"
+	"github.com/graymeta/gmkit/lock"
+	"github.com/graymeta/gmkit/schedule"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	run := func(t *testing.T, s *schedule.Scheduler, d time.Duration) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), d)
+		defer cancel()
+		require.NoError(t, s.Run(ctx))
+	}
+
+	t.Run("each run executes on one replica", func(t *testing.T) {
+		l := lock.NewMemoryLocker()
+
+		var mu sync.Mutex
+		runs := map[string]int{}
+		var wg sync.WaitGroup
+		for _, id := range []string{"r1", "r2", "r3"} {
+			s := schedule.New(l, id)
+			id := id
+			require.NoError(t, s.Register("job", "@every 100ms", func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				runs[id]++
+				return nil
+			}))
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				run(t, s, 550*time.Millisecond)
+			}()
+		}
+		wg.Wait()
+
+		var total int
+		for _, n := range runs {
+			total += n
+		}
+		assert.InDelta(t, 5, total, 1)
+	})
+
+	t.Run("releases the locks of previous runs", func(t *testing.T) {
+		l := lock.NewMemoryLocker()
+
+		var mu sync.Mutex
+		var runs int
+		var wg sync.WaitGroup
+		for _, id := range []string{"r1", "r2"} {
+			s := schedule.New(l, id)
+			require.NoError(t, s.Register("job", "@every 10ms", func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				runs++
+				return nil
+			}, schedule.LockTTL(time.Hour)))
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				run(t, s, 300*time.Millisecond)
+			}()
+		}
+		wg.Wait()
+
+		assert.True(t, runs > 10, "runs %d", runs)
+		infos, err := l.List("schedule:")
+		require.NoError(t, err)
+		assert.True(t, len(infos) <= 2, "locks %d", len(infos))
+	})
+
+	t.Run("retries failed runs", func(t *testing.T) {
+		s := schedule.New(lock.NewMemoryLocker(), "r1")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls int
+		boff := backoff.New(
+			backoff.InitBackoff(time.Millisecond),
+			backoff.MaxBackoff(time.Millisecond),
+			backoff.MaxCalls(3),
+		)
+		require.NoError(t, s.Register("job", "@every 200ms", func(context.Context) error {
+			calls++
+			if calls == 1 {
+				return errors.New("failed")
+			}
+			cancel()
+			return nil
+		}, schedule.Backoff(boff)))
+
+		require.NoError(t, s.Run(ctx))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("duplicate and invalid jobs", func(t *testing.T) {
+		s := schedule.New(lock.NewMemoryLocker(), "r1")
+		noop := func(context.Context) error { return nil }
+
+		require.NoError(t, s.Register("job", "@hourly", noop))
+		assert.Equal(t, schedule.ErrDuplicateJob, s.Register("job", "@daily", noop))
+		assert.Error(t, s.Register("other", "not cron", noop))
+	})
+
+	t.Run("missed runs", func(t *testing.T) {
+		tests := []struct {
+			policy schedule.MissedRunPolicy
+			gap    func(t *testing.T, gap time.Duration)
+		}{
+			{
+				schedule.SkipMissed,
+				func(t *testing.T, gap time.Duration) {
+					assert.True(t, gap >= 50*time.Millisecond, "gap %s", gap)
+				},
+			},
+			{
+				schedule.RunMissedOnce,
+				func(t *testing.T, gap time.Duration) {
+					assert.True(t, gap < 50*time.Millisecond, "gap %s", gap)
+				},
+			},
+		}
+
+		for _, test := range tests {
+			s := schedule.New(lock.NewMemoryLocker(), "r1")
+
+			var ended, started time.Time
+			var calls int
+			require.NoError(t, s.Register("job", "@every 200ms", func(context.Context) error {
+				calls++
+				switch calls {
+				case 1:
+					// overrun the following two runs
+					time.Sleep(500 * time.Millisecond)
+					ended = time.Now()
+				case 2:
+					started = time.Now()
+				}
+				return nil
+			}, schedule.MissedRuns(test.policy)))
+
+			run(t, s, 950*time.Millisecond)
+			require.True(t, calls >= 2)
+			test.gap(t, started.Sub(ended))
+		}
+	})
+
+	t.Run("graceful shutdown", func(t *testing.T) {
+		s := schedule.New(lock.NewMemoryLocker(), "r1", schedule.WithGracePeriod(time.Minute))
+
+		started := make(chan struct{})
+		var completed bool
+		require.NoError(t, s.Register("job", "@every 100ms", func(ctx context.Context) error {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			completed = ctx.Err() == nil
+			return nil
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-started
+			cancel()
+		}()
+		require.NoError(t, s.Run(ctx))
+		assert.True(t, completed)
+	})
+
+	t.Run("cancels jobs after the grace period", func(t *testing.T) {
+		s := schedule.New(lock.NewMemoryLocker(), "r1", schedule.WithGracePeriod(10*time.Millisecond))
+
+		started := make(chan struct{})
+		var cancelled bool
+		require.NoError(t, s.Register("job", "@every 100ms", func(ctx context.Context) error {
+			close(started)
+			select {
+			case <-ctx.Done():
+				cancelled = true
+			case <-time.After(time.Second):
+			}
+			return ctx.Err()
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-started
+			cancel()
+		}()
+
+		start := time.Now()
+		require.NoError(t, s.Run(ctx))
+		assert.True(t, cancelled)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+}
@@ -0,0 +1,302 @@
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// MockServer is an httptest.Server that serves responses for the requests
+// it has been told to expect. Any expectation that has not been met, and any
+// request that was not expected, is reported as a test failure when the test
+// completes.
+type MockServer struct {
+	*httptest.Server
+
+	t            testing.TB
+	mu           sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+}
+
+// NewMockServer starts a MockServer that is closed and verified when the test
+// completes.
+func NewMockServer(t testing.TB) *MockServer {
+	t.Helper()
+
+	m := &MockServer{t: t}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	t.Cleanup(func() {
+		m.Close()
+		m.verify()
+	})
+	return m
+}
+
+// Expect registers an expectation for a request with the provided method and
+// path. Expectations are matched in the order they are registered.
+func (m *MockServer) Expect(method, path string) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &Expectation{
+		method: method,
+		path:   path,
+		query:  make(url.Values),
+		header: make(http.Header),
+	}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+func (m *MockServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	m.mu.Lock()
+	var matched *Expectation
+	for _, e := range m.expectations {
+		if e.exhausted() || !e.matches(r, body) {
+			continue
+		}
+		matched = e
+		break
+	}
+	if matched == nil {
+		m.unexpected = append(m.unexpected, describeRequest(r, body))
+		m.mu.Unlock()
+		http.Error(w, "unexpected request", http.StatusNotImplemented)
+		return
+	}
+	resp := matched.nextResponse()
+	m.mu.Unlock()
+
+	for k, vals := range resp.header {
+		for _, v := range vals {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+func (m *MockServer) verify() {
+	m.t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, req := range m.unexpected {
+		m.t.Errorf("mock server received unexpected request: %s", req)
+	}
+	for _, e := range m.expectations {
+		if e.calls < e.wantCalls() {
+			m.t.Errorf("mock server expected %s to be called %d time(s), was called %d time(s)", e, e.wantCalls(), e.calls)
+		}
+	}
+}
+
+// Expectation describes a request the MockServer expects to receive and the
+// response(s) it should serve for it.
+type Expectation struct {
+	method, path string
+	query        url.Values
+	header       http.Header
+	body         []byte
+	jsonBody     interface{}
+	hasJSONBody  bool
+
+	responses []mockResponse
+	times     int
+	calls     int
+}
+
+type mockResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// WithQuery requires the request to have the provided query params.
+func (e *Expectation) WithQuery(key, value string, pairs ...string) *Expectation {
+	e.query.Add(key, value)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		e.query.Add(pairs[i], pairs[i+1])
+	}
+	return e
+}
+
+// WithHeader requires the request to have a header with the provided value.
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	e.header.Add(key, value)
+	return e
+}
+
+// WithBody requires the request body to match the provided body exactly.
+func (e *Expectation) WithBody(body string) *Expectation {
+	e.body = []byte(body)
+	return e
+}
+
+// WithJSONBody requires the request body to be JSON that is equivalent to the
+// JSON encoding of v.
+func (e *Expectation) WithJSONBody(v interface{}) *Expectation {
+	e.jsonBody = normalizeJSON(v)
+	e.hasJSONBody = true
+	return e
+}
+
+// Respond adds a response to the expectation. Calling Respond multiple times
+// serves the responses in sequence, with the last response being served for
+// any call beyond the number of responses. A body that is a string or []byte
+// is written as is, any other body is encoded as JSON.
+func (e *Expectation) Respond(status int, body interface{}) *Expectation {
+	resp := mockResponse{
+		status: status,
+		header: make(http.Header),
+	}
+	switch b := body.(type) {
+	case nil:
+	case string:
+		resp.body = []byte(b)
+	case []byte:
+		resp.body = b
+	default:
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(b); err != nil {
+			panic(fmt.Sprintf("encoding mock response body: %v", err))
+		}
+		resp.body = buf.Bytes()
+		resp.header.Set("Content-Type", "application/json")
+	}
+	e.responses = append(e.responses, resp)
+	return e
+}
+
+// RespondHeader sets a header on the most recently added response.
+func (e *Expectation) RespondHeader(key, value string) *Expectation {
+	if len(e.responses) == 0 {
+		e.Respond(http.StatusOK, nil)
+	}
+	e.responses[len(e.responses)-1].header.Set(key, value)
+	return e
+}
+
+// Times sets the number of times the expectation must be met. By default an
+// expectation must be met once for each response that has been added.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// String describes the expectation.
+func (e *Expectation) String() string {
+	s := e.method + " " + e.path
+	if len(e.query) > 0 {
+		s += "?" + e.query.Encode()
+	}
+	return s
+}
+
+func (e *Expectation) wantCalls() int {
+	if e.times > 0 {
+		return e.times
+	}
+	if len(e.responses) > 0 {
+		return len(e.responses)
+	}
+	return 1
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.calls >= e.wantCalls()
+}
+
+func (e *Expectation) nextResponse() mockResponse {
+	e.calls++
+	if len(e.responses) == 0 {
+		return mockResponse{status: http.StatusOK}
+	}
+	i := e.calls - 1
+	if i >= len(e.responses) {
+		i = len(e.responses) - 1
+	}
+	return e.responses[i]
+}
+
+func (e *Expectation) matches(r *http.Request, body []byte) bool {
+	if r.Method != e.method || r.URL.Path != e.path {
+		return false
+	}
+
+	q := r.URL.Query()
+	for k, vals := range e.query {
+		if !reflect.DeepEqual(q[k], vals) {
+			return false
+		}
+	}
+
+	for k, vals := range e.header {
+		if !reflect.DeepEqual(r.Header.Values(k), vals) {
+			return false
+		}
+	}
+
+	if e.body != nil && !bytes.Equal(e.body, body) {
+		return false
+	}
+
+	if e.hasJSONBody {
+		var actual interface{}
+		if err := json.Unmarshal(body, &actual); err != nil {
+			return false
+		}
+		if !reflect.DeepEqual(e.jsonBody, actual) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizeJSON round trips v through JSON so that it can be compared against
+// a decoded request body.
+func normalizeJSON(v interface{}) interface{} {
+	var b []byte
+	switch body := v.(type) {
+	case string:
+		b = []byte(body)
+	case []byte:
+		b = body
+	default:
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			panic(fmt.Sprintf("encoding expected json body: %v", err))
+		}
+	}
+
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		panic(fmt.Sprintf("decoding expected json body: %v", err))
+	}
+	return out
+}
+
+func describeRequest(r *http.Request, body []byte) string {
+	parts := []string{r.Method, r.URL.RequestURI()}
+	if len(body) > 0 {
+		parts = append(parts, fmt.Sprintf("body=%q", body))
+	}
+	return strings.Join(parts, " ")
+}
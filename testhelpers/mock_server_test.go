@@ -0,0 +1,107 @@
+package testhelpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTB records the failures reported to it, and runs its cleanups when the
+// fake test completes.
+type fakeTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) complete() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestMockServer(t *testing.T) {
+	get := func(t *testing.T, m *MockServer, path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(m.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(b)
+	}
+
+	t.Run("met expectations", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		m := NewMockServer(tb)
+		m.Expect(http.MethodGet, "/foo").Respond(http.StatusOK, "foo")
+
+		status, body := get(t, m, "/foo")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "foo", body)
+
+		tb.complete()
+		assert.Empty(t, tb.errors)
+	})
+
+	t.Run("unmet expectations", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		m := NewMockServer(tb)
+		m.Expect(http.MethodGet, "/foo")
+		m.Expect(http.MethodPost, "/bar").Times(2)
+
+		get(t, m, "/foo")
+
+		tb.complete()
+		require.Len(t, tb.errors, 1)
+		assert.Contains(t, tb.errors[0], "POST /bar to be called 2 time(s), was called 0 time(s)")
+	})
+
+	t.Run("unexpected calls", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		m := NewMockServer(tb)
+		m.Expect(http.MethodGet, "/foo")
+
+		get(t, m, "/foo")
+		status, _ := get(t, m, "/foo?again=true")
+		assert.Equal(t, http.StatusNotImplemented, status)
+
+		tb.complete()
+		require.Len(t, tb.errors, 1)
+		assert.True(t, strings.HasSuffix(tb.errors[0], "unexpected request: GET /foo?again=true"), tb.errors[0])
+	})
+
+	t.Run("sequenced responses", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		m := NewMockServer(tb)
+		m.Expect(http.MethodGet, "/foo").
+			Respond(http.StatusServiceUnavailable, "retry").
+			Respond(http.StatusOK, "ok")
+
+		var got []string
+		for i := 0; i < 3; i++ {
+			status, body := get(t, m, "/foo")
+			got = append(got, fmt.Sprintf("%d %s", status, body))
+		}
+		assert.Equal(t, []string{"503 retry", "200 ok", "501 unexpected request\n"}, got)
+
+		tb.complete()
+		require.Len(t, tb.errors, 1)
+		assert.Contains(t, tb.errors[0], "unexpected request: GET /foo")
+	})
+}
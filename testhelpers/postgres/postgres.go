@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	// registers the postgres driver.
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultURL = "postgres://postgres@localhost:5432/postgres?sslmode=disable"
+
+// Setup creates a new Postgres connection pool. It connects to the database in
+// the POSTGRES_URL environment variable, or a local Postgres server if unset.
+// Tables created by the test are not dropped, use names unique to the test.
+func Setup(t *testing.T) *sqlx.DB {
+	url := os.Getenv("POSTGRES_URL")
+	if url == "" {
+		url = defaultURL
+	}
+
+	db, err := sqlx.Connect("postgres", url)
+	require.NoError(t, err)
+	return db
+}
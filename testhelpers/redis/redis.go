@@ -12,6 +12,12 @@ const db = 15
 // Setup creates a new Redis pool. It connects to a Redis server and clears out the DB
 // first before returning the connection pool.
 func Setup(t *testing.T) *redis.Pool {
+	return SetupDB(t, db)
+}
+
+// SetupDB is like Setup, but uses the given DB number. Pools using different
+// DBs can stand in for independent Redis servers.
+func SetupDB(t *testing.T, db int) *redis.Pool {
 	pool := &redis.Pool{
 		// Other pool configuration not shown in this example.
 		Dial: func() (redis.Conn, error) {